@@ -0,0 +1,305 @@
+// Package cache persists metadata lookups and manual choices between runs.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/florianehmke/plexname/search"
+)
+
+const (
+	responsesFile = "responses.json"
+	choicesFile   = "choices.json"
+)
+
+// Cache of raw search responses and of the choices a user made.
+//
+// Responses expire after the TTL, choices are kept until they are
+// forgotten or the cache is cleared.
+type Cache struct {
+	dir string
+	ttl time.Duration
+
+	responses map[string]Response
+	choices   map[string]Choice
+
+	// changed keys since the last save and whether all entries were
+	// cleared, per file. Only these are written over what is on disk.
+	changed map[string]map[string]bool
+	cleared map[string]bool
+}
+
+// Response of a metadata provider, as it was received.
+type Response struct {
+	Kind   string          `json:"kind"` // e.g. tmdb-search
+	Title  string          `json:"title"`
+	Year   int             `json:"year,omitempty"`
+	Stored time.Time       `json:"stored"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Choice the user made for a query.
+type Choice struct {
+	Kind   string        `json:"kind"` // movie or tv
+	Query  search.Query  `json:"query"`
+	Result search.Result `json:"result"`
+	Stored time.Time     `json:"stored"`
+}
+
+// Dir returns the plexname cache directory, following the XDG base directory spec.
+func Dir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "plexname")
+}
+
+// Open the cache stored in dir.
+func Open(dir string, ttl time.Duration) (*Cache, error) {
+	c := &Cache{
+		dir:       dir,
+		ttl:       ttl,
+		responses: map[string]Response{},
+		choices:   map[string]Choice{},
+		changed:   map[string]map[string]bool{responsesFile: {}, choicesFile: {}},
+		cleared:   map[string]bool{},
+	}
+	if err := c.load(responsesFile, &c.responses); err != nil {
+		return nil, err
+	}
+	if err := c.load(choicesFile, &c.choices); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Responses returns all stored responses, sorted by kind and title.
+func (c *Cache) Responses() []Response {
+	var responses []Response
+	for _, r := range c.responses {
+		responses = append(responses, r)
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].Kind != responses[j].Kind {
+			return responses[i].Kind < responses[j].Kind
+		}
+		return responses[i].Title < responses[j].Title
+	})
+	return responses
+}
+
+// Choices returns all remembered choices, sorted by kind and title.
+func (c *Cache) Choices() []Choice {
+	var choices []Choice
+	for _, ch := range c.choices {
+		choices = append(choices, ch)
+	}
+	sort.Slice(choices, func(i, j int) bool {
+		if choices[i].Kind != choices[j].Kind {
+			return choices[i].Kind < choices[j].Kind
+		}
+		return choices[i].Query.Title < choices[j].Query.Title
+	})
+	return choices
+}
+
+// Expired reports whether r is older than the TTL.
+func (c *Cache) Expired(r Response) bool {
+	return time.Since(r.Stored) > c.ttl
+}
+
+// Clear removes everything from the cache.
+func (c *Cache) Clear() error {
+	c.responses = map[string]Response{}
+	c.choices = map[string]Choice{}
+	c.cleared[responsesFile] = true
+	c.cleared[choicesFile] = true
+	if err := c.save(responsesFile); err != nil {
+		return err
+	}
+	return c.save(choicesFile)
+}
+
+// Forget removes all responses and choices for the given title and
+// returns how many entries were removed. Responses that are keyed by
+// the id of a movie or series of the title, e.g. its episodes, go too.
+func (c *Cache) Forget(title string) (int, error) {
+	title = normalize(title)
+	removed := 0
+	ids := map[string]bool{}
+	for k, r := range c.responses {
+		if normalize(r.Title) == title || normalize(detailsTitle(r)) == title {
+			for _, id := range resultIDs(r) {
+				ids[id] = true
+			}
+			delete(c.responses, k)
+			c.changed[responsesFile][k] = true
+			removed++
+		}
+	}
+	for k, ch := range c.choices {
+		if normalize(ch.Query.Title) == title || normalize(ch.Result.Title) == title {
+			if ch.Result.ID != 0 {
+				ids[choiceID(ch)] = true
+			}
+			delete(c.choices, k)
+			c.changed[choicesFile][k] = true
+			removed++
+		}
+	}
+	for k, r := range c.responses {
+		if id, ok := responseID(r); ok && ids[id] {
+			delete(c.responses, k)
+			c.changed[responsesFile][k] = true
+			removed++
+		}
+	}
+	if err := c.save(responsesFile); err != nil {
+		return removed, err
+	}
+	return removed, c.save(choicesFile)
+}
+
+// Choice implements search.ChoiceStore.
+func (c *Cache) Choice(kind string, query search.Query) (search.Result, bool) {
+	ch, ok := c.choices[key(kind, query.Title, query.Year)]
+	return ch.Result, ok
+}
+
+// Remember implements search.ChoiceStore.
+func (c *Cache) Remember(kind string, query search.Query, result search.Result) error {
+	k := key(kind, query.Title, query.Year)
+	c.choices[k] = Choice{
+		Kind:   kind,
+		Query:  query,
+		Result: result,
+		Stored: time.Now(),
+	}
+	c.changed[choicesFile][k] = true
+	return c.save(choicesFile)
+}
+
+// get unmarshals a fresh response into v and reports whether there was one.
+func (c *Cache) get(kind, title string, year int, v interface{}) bool {
+	r, ok := c.responses[key(kind, title, year)]
+	if !ok || c.Expired(r) {
+		return false
+	}
+	return json.Unmarshal(r.Data, v) == nil
+}
+
+func (c *Cache) put(kind, title string, year int, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal of %s response failed: %v", kind, err)
+	}
+	k := key(kind, title, year)
+	c.responses[k] = Response{
+		Kind:   kind,
+		Title:  title,
+		Year:   year,
+		Stored: time.Now(),
+		Data:   data,
+	}
+	c.changed[responsesFile][k] = true
+	return c.save(responsesFile)
+}
+
+func (c *Cache) load(file string, v interface{}) error {
+	data, err := ioutil.ReadFile(filepath.Join(c.dir, file))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read cache: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("could not parse cache %s: %v", file, err)
+	}
+	return nil
+}
+
+// save writes the changes to file. Other processes, e.g. a review next to
+// a watch, share the cache: under the lock the file is read again, only
+// the changed entries are put over it and the result replaces the file
+// and the entries in memory.
+func (c *Cache) save(file string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("could not create cache directory: %v", err)
+	}
+	unlock, err := lock(c.dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries := map[string]json.RawMessage{}
+	if !c.cleared[file] {
+		if err := c.load(file, &entries); err != nil {
+			return err
+		}
+	}
+	for k := range c.changed[file] {
+		var v interface{}
+		var ok bool
+		if file == responsesFile {
+			v, ok = c.responses[k]
+		} else {
+			v, ok = c.choices[k]
+		}
+		if !ok {
+			delete(entries, k)
+			continue
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshal of cache failed: %v", err)
+		}
+		entries[k] = data
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal of cache failed: %v", err)
+	}
+	tmp := filepath.Join(c.dir, file+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("could not write cache: %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(c.dir, file)); err != nil {
+		return fmt.Errorf("could not write cache: %v", err)
+	}
+	c.changed[file] = map[string]bool{}
+	c.cleared[file] = false
+
+	if file == responsesFile {
+		responses := map[string]Response{}
+		if err := json.Unmarshal(data, &responses); err != nil {
+			return fmt.Errorf("could not parse cache %s: %v", file, err)
+		}
+		c.responses = responses
+		return nil
+	}
+	choices := map[string]Choice{}
+	if err := json.Unmarshal(data, &choices); err != nil {
+		return fmt.Errorf("could not parse cache %s: %v", file, err)
+	}
+	c.choices = choices
+	return nil
+}
+
+func key(kind, title string, year int) string {
+	return fmt.Sprintf("%s|%s|%d", kind, normalize(title), year)
+}
+
+func normalize(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
@@ -0,0 +1,202 @@
+package cache_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/florianehmke/plexname/cache"
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tmdb"
+	"github.com/florianehmke/plexname/tvdb"
+)
+
+type countingTMDB struct {
+	tmdb.Client
+	calls int
+}
+
+func (c *countingTMDB) Search(query string, year int, page int) (*tmdb.SearchResponse, error) {
+	c.calls++
+	return &tmdb.SearchResponse{Results: []tmdb.SearchResult{{Title: query}}}, nil
+}
+
+func TestResponses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plexname-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := &countingTMDB{}
+	c, err := cache.Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := c.WrapTMDB(client, tmdb.DefaultLanguage)
+	cached.Search("Title", 1999, 0)
+	cached.Search("title", 1999, 0)
+	if client.calls != 1 {
+		t.Errorf("expected 1 call, got %d", client.calls)
+	}
+
+	// Responses in another language are fetched on their own.
+	c.WrapTMDB(client, "de-DE").Search("Title", 1999, 0)
+	if client.calls != 2 {
+		t.Errorf("expected 2 calls, got %d", client.calls)
+	}
+
+	// A second process sees the same cache.
+	c, err = cache.Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.WrapTMDB(client, tmdb.DefaultLanguage).Search("Title", 1999, 0)
+	if err != nil || r.Results[0].Title != "Title" {
+		t.Errorf("expected cached response, got %v (%v)", r, err)
+	}
+	if client.calls != 2 {
+		t.Errorf("expected 2 calls, got %d", client.calls)
+	}
+
+	// Expired responses are fetched again.
+	c, err = cache.Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.WrapTMDB(client, tmdb.DefaultLanguage).Search("Title", 1999, 0)
+	if client.calls != 3 {
+		t.Errorf("expected 3 calls, got %d", client.calls)
+	}
+}
+
+func TestChoices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plexname-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := cache.Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := search.Query{Title: "doctor who"}
+	if err := c.Remember(search.KindTV, query, search.Result{Title: "Doctor Who", Year: 2005}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = cache.Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := c.Choice(search.KindTV, query); !ok || r.Year != 2005 {
+		t.Errorf("expected remembered choice, got %v", r)
+	}
+	if _, ok := c.Choice(search.KindMovie, query); ok {
+		t.Error("expected no choice for movies")
+	}
+
+	n, err := c.Forget("Doctor Who")
+	if err != nil || n != 1 {
+		t.Errorf("expected 1 forgotten entry, got %d (%v)", n, err)
+	}
+	if _, ok := c.Choice(search.KindTV, query); ok {
+		t.Error("expected choice to be forgotten")
+	}
+}
+
+func TestForget_IDKeyedResponses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plexname-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := cache.Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tvdbClient := c.WrapTVDB(mock.NewMockTVDBWithEpisodes(tvdb.SearchResponse{Results: []tvdb.SearchResult{
+		{ID: 78804, Title: "Doctor Who"},
+	}}, []tvdb.Episode{{AiredSeason: 1, AiredEpisodeNumber: 1}}, nil))
+	otherClient := c.WrapTVDB(mock.NewMockTVDBWithEpisodes(tvdb.SearchResponse{Results: []tvdb.SearchResult{
+		{ID: 1, Title: "Other Show"},
+	}}, []tvdb.Episode{{AiredSeason: 1, AiredEpisodeNumber: 1}}, nil))
+	tmdbClient := c.WrapTMDB(mock.NewMockTMDBWithTitles(tmdb.SearchResponse{Results: []tmdb.SearchResult{
+		{ID: 5, Title: "Doctor Who"},
+	}}, map[int][]tmdb.AlternativeTitle{5: {{Title: "Dr. Who"}}}, nil), "")
+
+	for _, lookup := range []func() error{
+		func() error { _, err := tvdbClient.Search("doctor who"); return err },
+		func() error { _, err := tvdbClient.Episodes(78804); return err },
+		func() error { _, err := tvdbClient.Series(78804); return err },
+		func() error { _, err := otherClient.Search("other show"); return err },
+		func() error { _, err := otherClient.Episodes(1); return err },
+		func() error { _, err := tmdbClient.Search("doctor who", 0, 0); return err },
+		func() error { _, err := tmdbClient.AlternativeTitles(5); return err },
+	} {
+		if err := lookup(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := c.Forget("Doctor Who")
+	if err != nil || n != 5 {
+		t.Errorf("expected 5 forgotten entries, got %d (%v)", n, err)
+	}
+	for _, r := range c.Responses() {
+		if r.Title != "other show" && r.Title != "1" {
+			t.Errorf("expected %s %s to be forgotten", r.Kind, r.Title)
+		}
+	}
+	if len(c.Responses()) != 2 {
+		t.Errorf("expected the other show to be kept, got %v", c.Responses())
+	}
+}
+
+// TestSharedCache makes sure a long running process, e.g. a watch,
+// keeps the choices another process made since it opened the cache.
+func TestSharedCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plexname-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	watcher, err := cache.Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review, err := cache.Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := search.Query{Title: "doctor who"}
+	if err := review.Remember(search.KindTV, query, search.Result{Title: "Doctor Who", Year: 2005}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watcher.WrapTMDB(&countingTMDB{}, "").Search("other", 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := cache.Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Choice(search.KindTV, query); !ok {
+		t.Error("expected the choice of the other process to be kept")
+	}
+	if len(c.Responses()) != 1 {
+		t.Errorf("expected 1 response, got %d", len(c.Responses()))
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected only the cache files to be left, got %d files", len(files))
+	}
+}
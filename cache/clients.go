@@ -0,0 +1,324 @@
+package cache
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/florianehmke/plexname/log"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tmdb"
+	"github.com/florianehmke/plexname/tvdb"
+)
+
+// WrapTMDB returns a TMDB client that answers from c where possible. The
+// responses of clients with different metadata languages are kept apart.
+func (c *Cache) WrapTMDB(client tmdb.Client, language string) tmdb.Client {
+	return &tmdbClient{cache: c, client: client, language: language}
+}
+
+// WrapTVDB returns a TVDB client that answers from c where possible.
+func (c *Cache) WrapTVDB(client tvdb.Client) tvdb.Client {
+	return &tvdbClient{cache: c, client: client}
+}
+
+type tmdbClient struct {
+	cache    *Cache
+	client   tmdb.Client
+	language string
+}
+
+// localized returns the kind of a response that depends on the metadata
+// language, responses in the default language keep the plain kind.
+func (t *tmdbClient) localized(kind string) string {
+	if t.language == "" || t.language == tmdb.DefaultLanguage {
+		return kind
+	}
+	return kind + "-" + t.language
+}
+
+func (t *tmdbClient) Search(query string, year int, page int) (*tmdb.SearchResponse, error) {
+	kind := t.localized("tmdb-search")
+	if page > 1 {
+		kind += "-page-" + strconv.Itoa(page)
+	}
+	var response tmdb.SearchResponse
+	if t.cache.get(kind, query, year, &response) {
+		return &response, nil
+	}
+	r, err := t.client.Search(query, year, page)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store(kind, query, year, r)
+	return r, nil
+}
+
+func (t *tmdbClient) Movie(id int) (*tmdb.Movie, error) {
+	key, kind := strconv.Itoa(id), t.localized("tmdb-movie")
+	var movie tmdb.Movie
+	if t.cache.get(kind, key, 0, &movie) {
+		return &movie, nil
+	}
+	m, err := t.client.Movie(id)
+	if err != nil {
+		return m, err
+	}
+	t.cache.store(kind, key, 0, m)
+	return m, nil
+}
+
+func (t *tmdbClient) AlternativeTitles(id int) (*tmdb.AlternativeTitlesResponse, error) {
+	key := strconv.Itoa(id)
+	var response tmdb.AlternativeTitlesResponse
+	if t.cache.get("tmdb-alternative-titles", key, 0, &response) {
+		return &response, nil
+	}
+	r, err := t.client.AlternativeTitles(id)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store("tmdb-alternative-titles", key, 0, r)
+	return r, nil
+}
+
+func (t *tmdbClient) Translations(id int) (*tmdb.TranslationsResponse, error) {
+	key := strconv.Itoa(id)
+	var response tmdb.TranslationsResponse
+	if t.cache.get("tmdb-translations", key, 0, &response) {
+		return &response, nil
+	}
+	r, err := t.client.Translations(id)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store("tmdb-translations", key, 0, r)
+	return r, nil
+}
+
+func (t *tmdbClient) FindByIMDbID(imdbID string) (*tmdb.FindResponse, error) {
+	kind := t.localized("tmdb-find")
+	var response tmdb.FindResponse
+	if t.cache.get(kind, imdbID, 0, &response) {
+		return &response, nil
+	}
+	r, err := t.client.FindByIMDbID(imdbID)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store(kind, imdbID, 0, r)
+	return r, nil
+}
+
+func (t *tmdbClient) SearchTV(query string, year int, page int) (*tmdb.TVSearchResponse, error) {
+	kind := t.localized("tmdb-tv-search")
+	if page > 1 {
+		kind += "-page-" + strconv.Itoa(page)
+	}
+	var response tmdb.TVSearchResponse
+	if t.cache.get(kind, query, year, &response) {
+		return &response, nil
+	}
+	r, err := t.client.SearchTV(query, year, page)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store(kind, query, year, r)
+	return r, nil
+}
+
+func (t *tmdbClient) TV(id int) (*tmdb.TV, error) {
+	key, kind := strconv.Itoa(id), t.localized("tmdb-tv")
+	var tv tmdb.TV
+	if t.cache.get(kind, key, 0, &tv) {
+		return &tv, nil
+	}
+	r, err := t.client.TV(id)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store(kind, key, 0, r)
+	return r, nil
+}
+
+func (t *tmdbClient) Season(tvID int, season int) (*tmdb.Season, error) {
+	key, kind := strconv.Itoa(tvID)+"/"+strconv.Itoa(season), t.localized("tmdb-season")
+	var s tmdb.Season
+	if t.cache.get(kind, key, 0, &s) {
+		return &s, nil
+	}
+	r, err := t.client.Season(tvID, season)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store(kind, key, 0, r)
+	return r, nil
+}
+
+type tvdbClient struct {
+	cache  *Cache
+	client tvdb.Client
+}
+
+func (t *tvdbClient) Search(query string) (*tvdb.SearchResponse, error) {
+	var response tvdb.SearchResponse
+	if t.cache.get("tvdb-search", query, 0, &response) {
+		return &response, nil
+	}
+	r, err := t.client.Search(query)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store("tvdb-search", query, 0, r)
+	return r, nil
+}
+
+func (t *tvdbClient) SearchByIMDbID(imdbID string) (*tvdb.SearchResponse, error) {
+	var response tvdb.SearchResponse
+	if t.cache.get("tvdb-imdb-search", imdbID, 0, &response) {
+		return &response, nil
+	}
+	r, err := t.client.SearchByIMDbID(imdbID)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store("tvdb-imdb-search", imdbID, 0, r)
+	return r, nil
+}
+
+func (t *tvdbClient) Series(id int) (*tvdb.Series, error) {
+	key := strconv.Itoa(id)
+	var series tvdb.Series
+	if t.cache.get("tvdb-series", key, 0, &series) {
+		return &series, nil
+	}
+	r, err := t.client.Series(id)
+	if err != nil {
+		return r, err
+	}
+	t.cache.store("tvdb-series", key, 0, r)
+	return r, nil
+}
+
+func (t *tvdbClient) Episodes(seriesID int) ([]tvdb.Episode, error) {
+	id := strconv.Itoa(seriesID)
+	var episodes []tvdb.Episode
+	if t.cache.get("tvdb-episodes", id, 0, &episodes) {
+		return episodes, nil
+	}
+	episodes, err := t.client.Episodes(seriesID)
+	if err != nil {
+		return episodes, err
+	}
+	t.cache.store("tvdb-episodes", id, 0, episodes)
+	return episodes, nil
+}
+
+// store puts v into the cache, failing to do so is not fatal for a lookup.
+func (c *Cache) store(kind, title string, year int, v interface{}) {
+	if err := c.put(kind, title, year, v); err != nil {
+		log.Warnf("Could not cache %s response: %v", kind, err)
+	}
+}
+
+// Responses keyed by id belong to one of these groups, an id is only
+// unique within its group.
+const (
+	tmdbMovies = "tmdb-movie"
+	tmdbTV     = "tmdb-tv"
+	tvdbSeries = "tvdb-series"
+)
+
+// responseID returns the group and id of the movie or series a response
+// is keyed by, e.g. tmdb-tv|1399 for the seasons of a TMDB tv show.
+func responseID(r Response) (string, bool) {
+	var group string
+	switch {
+	case strings.HasPrefix(r.Kind, "tmdb-movie"),
+		strings.HasPrefix(r.Kind, "tmdb-alternative-titles"),
+		strings.HasPrefix(r.Kind, "tmdb-translations"):
+		group = tmdbMovies
+	case strings.HasPrefix(r.Kind, "tmdb-tv-search"):
+		return "", false
+	case strings.HasPrefix(r.Kind, "tmdb-tv"), strings.HasPrefix(r.Kind, "tmdb-season"):
+		group = tmdbTV
+	case r.Kind == "tvdb-series", r.Kind == "tvdb-episodes":
+		group = tvdbSeries
+	default:
+		return "", false
+	}
+	// Seasons are keyed by tv id and season number, e.g. 1399/2.
+	return group + "|" + strings.SplitN(r.Title, "/", 2)[0], true
+}
+
+// resultIDs returns the group and id of all results of a search response.
+func resultIDs(r Response) []string {
+	var group string
+	var ids []int
+	switch {
+	case strings.HasPrefix(r.Kind, "tmdb-search"):
+		var response tmdb.SearchResponse
+		if json.Unmarshal(r.Data, &response) == nil {
+			for _, result := range response.Results {
+				ids = append(ids, result.ID)
+			}
+		}
+		group = tmdbMovies
+	case strings.HasPrefix(r.Kind, "tmdb-tv-search"):
+		var response tmdb.TVSearchResponse
+		if json.Unmarshal(r.Data, &response) == nil {
+			for _, result := range response.Results {
+				ids = append(ids, result.ID)
+			}
+		}
+		group = tmdbTV
+	case r.Kind == "tvdb-search":
+		var response tvdb.SearchResponse
+		if json.Unmarshal(r.Data, &response) == nil {
+			for _, result := range response.Results {
+				ids = append(ids, result.ID)
+			}
+		}
+		group = tvdbSeries
+	default:
+		if id, ok := responseID(r); ok {
+			return []string{id}
+		}
+	}
+	var result []string
+	for _, id := range ids {
+		result = append(result, group+"|"+strconv.Itoa(id))
+	}
+	return result
+}
+
+// detailsTitle returns the title of a movie or series details response,
+// these are keyed by id and not by title.
+func detailsTitle(r Response) string {
+	var details struct {
+		Title      string `json:"title"`
+		Name       string `json:"name"`
+		SeriesName string `json:"seriesName"`
+	}
+	switch {
+	case strings.HasPrefix(r.Kind, "tmdb-movie"),
+		strings.HasPrefix(r.Kind, "tmdb-tv") && !strings.HasPrefix(r.Kind, "tmdb-tv-search"),
+		r.Kind == "tvdb-series":
+		if json.Unmarshal(r.Data, &details) != nil {
+			return ""
+		}
+	}
+	return details.Title + details.Name + details.SeriesName
+}
+
+// choiceID returns the group and id of the result of a choice.
+func choiceID(ch Choice) string {
+	group := tmdbMovies
+	if ch.Kind == search.KindTV {
+		group = tvdbSeries
+		if ch.Result.Provider == search.ProviderTMDB {
+			group = tmdbTV
+		}
+	}
+	return group + "|" + strconv.Itoa(ch.Result.ID)
+}
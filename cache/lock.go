@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockFile = "cache.lock"
+
+	// lockTimeout is how long to wait for another process to save.
+	lockTimeout = 10 * time.Second
+	lockRetry   = 50 * time.Millisecond
+
+	// staleLock is the age of a lock that was left behind by a crash.
+	staleLock = time.Minute
+)
+
+// lock takes the lock of the cache in dir, waiting while another process
+// holds it, and returns the func that releases it.
+func lock(dir string) (func(), error) {
+	path := filepath.Join(dir, lockFile)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("could not lock cache: %v", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("could not lock cache: it is held by another process")
+		}
+		time.Sleep(lockRetry)
+	}
+}
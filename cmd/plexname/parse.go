@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/florianehmke/plexname/log"
+	"github.com/florianehmke/plexname/parser"
+)
+
+// parsedName is the json representation of a parse result, enums are
+// rendered by their String methods and unset fields are left out.
+type parsedName struct {
+	Name string `json:"name"`
+
+	Title           string `json:"title,omitempty"`
+	MediaType       string `json:"media_type,omitempty"`
+	Year            int    `json:"year,omitempty"`
+	Season          int    `json:"season,omitempty"`
+	Episode         int    `json:"episode,omitempty"`
+	LastEpisode     int    `json:"last_episode,omitempty"`
+	AbsoluteEpisode int    `json:"absolute_episode,omitempty"`
+	AirDate         string `json:"air_date,omitempty"`
+	Special         string `json:"special,omitempty"`
+	ReleaseGroup    string `json:"release_group,omitempty"`
+	Edition         string `json:"edition,omitempty"`
+
+	Resolution   string `json:"resolution,omitempty"`
+	Source       string `json:"source,omitempty"`
+	Language     string `json:"language,omitempty"`
+	Remux        string `json:"remux,omitempty"`
+	Proper       string `json:"proper,omitempty"`
+	DualLanguage string `json:"dual_language,omitempty"`
+
+	VideoCodec    string `json:"video_codec,omitempty"`
+	AudioCodec    string `json:"audio_codec,omitempty"`
+	AudioChannels string `json:"audio_channels,omitempty"`
+	HDR           string `json:"hdr,omitempty"`
+	BitDepth      string `json:"bit_depth,omitempty"`
+
+	Explanation []parser.Provenance `json:"explanation,omitempty"`
+}
+
+func newParsedName(name string, r parser.Result) parsedName {
+	pn := parsedName{
+		Name:            name,
+		Title:           r.Title,
+		MediaType:       enum(r.MediaType),
+		Year:            r.Year,
+		Season:          r.Season,
+		Episode:         r.Episode,
+		LastEpisode:     r.LastEpisode,
+		AbsoluteEpisode: r.AbsoluteEpisode,
+		Special:         enum(r.Special),
+		ReleaseGroup:    r.ReleaseGroup,
+		Edition:         r.Edition,
+		Resolution:      enum(r.Resolution),
+		Source:          enum(r.Source),
+		Language:        enum(r.Language),
+		Remux:           enum(r.Remux),
+		Proper:          enum(r.Proper),
+		DualLanguage:    enum(r.DualLanguage),
+		VideoCodec:      enum(r.VideoCodec),
+		AudioCodec:      enum(r.AudioCodec),
+		AudioChannels:   enum(r.AudioChannels),
+		HDR:             enum(r.HDR),
+		BitDepth:        enum(r.BitDepth),
+	}
+	if !r.AirDate.IsZero() {
+		pn.AirDate = r.AirDate.Format("2006-01-02")
+	}
+	return pn
+}
+
+// enum returns the string representation of e, empty if it is not set.
+func enum(e fmt.Stringer) string {
+	if s := e.String(); s != "--" {
+		return s
+	}
+	return ""
+}
+
+// parseNames prints the parse results of the given names, or of the
+// names read line by line from stdin if there are none. With -json each
+// result is printed as a json object on a line of its own, with -explain
+// along with the token, rule and confidence that decided each field.
+func parseNames(args []string) {
+	flags := flag.NewFlagSet("parse", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Println("plexname parse")
+		fmt.Println("  Print what plexname reads from release names, without searching or renaming.")
+		fmt.Println("  The names are read line by line from stdin if none are given.")
+		fmt.Println()
+		fmt.Println("Usage: ")
+		fmt.Println("  plexname parse [-json] [-explain] [name]...")
+		fmt.Println()
+		fmt.Println("Options:")
+		flags.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Example:")
+		fmt.Println("  ls downloads | plexname parse -json")
+	}
+	asJSON := flags.Bool("json", false, "print one json object per name")
+	explain := flags.Bool("explain", false, "print where the value of each field came from")
+	flags.Parse(args)
+
+	names := flags.Args()
+	if len(names) == 0 {
+		var err error
+		if names, err = readNames(os.Stdin); err != nil {
+			log.Error(fmt.Sprintf("parse failed: %v", err))
+			os.Exit(1)
+		}
+	}
+
+	var err error
+	if *asJSON {
+		err = printJSON(os.Stdout, names, *explain)
+	} else {
+		err = printText(os.Stdout, names, *explain)
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("parse failed: %v", err))
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// readNames reads the non-empty lines of r.
+func readNames(r io.Reader) ([]string, error) {
+	var names []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, scanner.Err()
+}
+
+func printJSON(out io.Writer, names []string, explain bool) error {
+	encoder := json.NewEncoder(out)
+	for _, name := range names {
+		r, e := parser.Explain(name, parser.Result{})
+		pn := newParsedName(name, r)
+		if explain {
+			pn.Explanation = e.All()
+		}
+		if err := encoder.Encode(pn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printText(out io.Writer, names []string, explain bool) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		_, e := parser.Explain(name, parser.Result{})
+		fmt.Fprintln(w, name)
+		for _, p := range e.All() {
+			if explain {
+				fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", p.Field, p.Value, p.Token, p.Rule, p.Confidence)
+			} else {
+				fmt.Fprintf(w, "  %s\t%s\n", p.Field, p.Value)
+			}
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/florianehmke/plexname/parser"
+)
+
+func TestNewParsedName(t *testing.T) {
+	tests := []struct {
+		result   parser.Result
+		expected parsedName
+	}{
+		{
+			result:   parser.Result{},
+			expected: parsedName{Name: "name"},
+		},
+		{
+			result: parser.Result{
+				Title:      "the matrix",
+				Year:       1999,
+				Resolution: parser.R1080,
+				Source:     parser.BluRay,
+				Remux:      parser.True,
+			},
+			expected: parsedName{
+				Name:       "name",
+				Title:      "the matrix",
+				Year:       1999,
+				Resolution: parser.R1080.String(),
+				Source:     parser.BluRay.String(),
+				Remux:      parser.True.String(),
+			},
+		},
+		{
+			result: parser.Result{
+				Title:   "the daily show",
+				AirDate: time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC),
+				Proper:  parser.False,
+			},
+			expected: parsedName{
+				Name:    "name",
+				Title:   "the daily show",
+				AirDate: "2021-03-15",
+				Proper:  parser.False.String(),
+			},
+		},
+	}
+	for _, tc := range tests {
+		if pn := newParsedName("name", tc.result); !reflect.DeepEqual(pn, tc.expected) {
+			t.Errorf("\nExpected: %+v\nReceived: %+v", tc.expected, pn)
+		}
+	}
+}
+
+func TestEnum(t *testing.T) {
+	if s := enum(parser.ResNA); s != "" {
+		t.Errorf("expected an unset enum to be omitted, got %s", s)
+	}
+	if s := enum(parser.R720); s != parser.R720.String() {
+		t.Errorf("expected %s, got %s", parser.R720, s)
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	names, err := readNames(strings.NewReader("Movie.1999.1080p.BluRay-GROUP.mkv\n\n  \nShow.S01E02.720p.mkv\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected the empty lines to be skipped, got %v", names)
+	}
+
+	var out bytes.Buffer
+	if err := printJSON(&out, names, true); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per name, got %q", out.String())
+	}
+
+	var movie map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &movie); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"name":       "Movie.1999.1080p.BluRay-GROUP.mkv",
+		"year":       float64(1999),
+		"resolution": parser.R1080.String(),
+		"source":     parser.BluRay.String(),
+	}
+	for k, v := range expected {
+		if movie[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, movie[k])
+		}
+	}
+	if _, ok := movie["hdr"]; ok {
+		t.Errorf("expected unset fields to be left out, got %v", movie["hdr"])
+	}
+	if _, ok := movie["explanation"]; !ok {
+		t.Error("expected an explanation with -explain")
+	}
+
+	var show struct {
+		Season     int    `json:"season"`
+		Episode    int    `json:"episode"`
+		Resolution string `json:"resolution"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &show); err != nil {
+		t.Fatal(err)
+	}
+	if show.Season != 1 || show.Episode != 2 || show.Resolution != parser.R720.String() {
+		t.Errorf("expected S01E02 in 720p, got %+v", show)
+	}
+}
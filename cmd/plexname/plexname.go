@@ -4,35 +4,393 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/florianehmke/plexname/cache"
 	"github.com/florianehmke/plexname/config"
 	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/journal"
 	"github.com/florianehmke/plexname/log"
 	"github.com/florianehmke/plexname/prompt"
 	"github.com/florianehmke/plexname/renamer"
 	"github.com/florianehmke/plexname/search"
 	"github.com/florianehmke/plexname/tmdb"
 	"github.com/florianehmke/plexname/tvdb"
+	"github.com/florianehmke/plexname/watch"
 )
 
 func main() {
 	flag.Usage = usage
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "undo":
+			undo(os.Args[2:])
+			return
+		case "plan":
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+			plan()
+			return
+		case "apply":
+			apply(os.Args[2:])
+			return
+		case "watch":
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+			watchDir()
+			return
+		case "review":
+			review(os.Args[2:])
+			return
+		case "cache":
+			manageCache(os.Args[2:])
+			return
+		case "parse":
+			parseNames(os.Args[2:])
+			return
+		}
+	}
+
 	arguments := renamer.GetParametersFromFlags()
-	r := renamer.New(
-		arguments,
-		search.NewSearcher(
-			tmdb.NewClient(tmdb.BaseURL, config.GetToken("tmdb")),
-			tvdb.NewClient(tvdb.BaseURL, config.GetToken("tvdb")),
-			prompt.NewPrompter(),
-		),
-		fs.NewFileSystem(arguments.DryRun),
-	)
-
-	if err := r.Run(); err != nil {
+
+	fileSystem, j := journaledFileSystem(arguments.DryRun)
+	r := renamer.New(arguments, newSearcher(arguments), fileSystem)
+
+	err := r.Run()
+	logJournal(j)
+	if err != nil {
 		log.Error(fmt.Sprintf("renaming failed: %v", err))
 		os.Exit(1)
 	}
+	if reportUnresolved(r) {
+		os.Exit(2)
+	}
+
+	log.Info("Yay, done!")
+	os.Exit(0)
+}
+
+func plan() {
+	var out string
+	flag.StringVar(&out, "o", "plan.json", "file the plan is written to")
+	arguments := renamer.GetParametersFromFlags()
+
+	r := renamer.New(arguments, newSearcher(arguments), fs.NewFileSystem(true))
+	p, err := r.Plan()
+	if err != nil {
+		log.Error(fmt.Sprintf("planning failed: %v", err))
+		os.Exit(1)
+	}
+	reportUnresolved(r)
+	if err := renamer.WritePlan(out, p); err != nil {
+		log.Error(fmt.Sprintf("planning failed: %v", err))
+		os.Exit(1)
+	}
+
+	log.Infof("Plan with %d moves written to %s, run it with: plexname apply %s", len(p.Items), out, out)
+	os.Exit(0)
+}
+
+func apply(args []string) {
+	if len(args) != 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	p, err := renamer.ReadPlan(args[0])
+	if err != nil {
+		log.Error(fmt.Sprintf("apply failed: %v", err))
+		os.Exit(1)
+	}
+
+	fileSystem, j := journaledFileSystem(false)
+	r := renamer.New(renamer.Parameters{}, nil, fileSystem)
+
+	err = r.Apply(p)
+	logJournal(j)
+	if err != nil {
+		log.Error(fmt.Sprintf("apply failed: %v", err))
+		os.Exit(1)
+	}
+
+	log.Info("Yay, done!")
+	os.Exit(0)
+}
+
+func watchDir() {
+	var interval, settle time.Duration
+	flag.DurationVar(&interval, "interval", 10*time.Second, "how often the source directory is scanned")
+	flag.DurationVar(&settle, "settle", time.Minute, "how long a new item has to stay unchanged before it is renamed")
+	arguments := renamer.GetParametersFromFlags()
+	options := renamerArgs(os.Args[1 : len(os.Args)-flag.NArg()])
+
+	if info, err := os.Stat(arguments.SourcePath); err != nil || !info.IsDir() {
+		log.Error(fmt.Sprintf("watch needs a source directory: %s", arguments.SourcePath))
+		os.Exit(1)
+	}
+
+	tmdbClient, tvdbClient, c := newClients(arguments)
+	queue := watch.DefaultQueue()
+	w := watch.New(filepath.FromSlash(arguments.SourcePath), interval, settle, func(path string) bool {
+		params := arguments
+		params.SourcePath = filepath.ToSlash(path)
+
+		recorder := &prompt.Recorder{}
+		searcher := search.NewSearcher(tmdbClient, tvdbClient, recorder)
+		if params.NonInteractive {
+			searcher = search.NewNonInteractiveSearcher(tmdbClient, tvdbClient, params.Threshold)
+		}
+		searcher = search.UseTVProvider(searcher, params.TVProvider)
+		if c != nil {
+			searcher = search.RememberChoices(searcher, c)
+		}
+		fileSystem, j := journaledFileSystem(params.DryRun)
+		r := renamer.New(params, searcher, fileSystem)
+
+		err := r.Run()
+		logJournal(j)
+		if err == nil && len(r.Unresolved()) == 0 {
+			log.Infof("Done: %s", path)
+			return true
+		}
+		if len(recorder.Questions) == 0 && len(r.Unresolved()) == 0 {
+			log.Errorf("renaming of %s failed, retrying after it settled again: %v", path, err)
+			return false
+		}
+		for _, u := range r.Unresolved() {
+			recorder.Questions = append(recorder.Questions, fmt.Sprintf("no good enough match for %s", u))
+		}
+		item := watch.Item{
+			Path:      path,
+			Target:    params.TargetPath,
+			Args:      options,
+			Questions: recorder.Questions,
+			Queued:    time.Now(),
+		}
+		if err := queue.Add(item); err != nil {
+			log.Errorf("queueing of %s failed, retrying after it settled again: %v", path, err)
+			return false
+		}
+		log.Warnf("Queued %s for review, run: plexname review", path)
+		return true
+	})
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	log.Infof("Watching %s", arguments.SourcePath)
+	if err := w.Run(stop); err != nil {
+		log.Error(fmt.Sprintf("watch failed: %v", err))
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func review(args []string) {
+	if len(args) != 0 {
+		usage()
+		os.Exit(1)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		log.Error(fmt.Sprintf("review failed: %v", err))
+		os.Exit(1)
+	}
+
+	queue := watch.DefaultQueue()
+	items, err := queue.Items()
+	if err != nil {
+		log.Error(fmt.Sprintf("review failed: %v", err))
+		os.Exit(1)
+	}
+	if len(items) == 0 {
+		log.Info("Nothing to review.")
+		os.Exit(0)
+	}
+
+	for _, item := range items {
+		if _, err := os.Stat(item.Path); os.IsNotExist(err) {
+			log.Warnf("Dropping %s from the review queue, it is gone", item.Path)
+			queue.Remove(item.Path)
+			continue
+		}
+
+		log.Infof("Reviewing: %s", item.Path)
+		cmd := exec.Command(self, append(interactiveArgs(item.Args), item.Path, item.Target)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			log.Errorf("review of %s failed: %v", item.Path, err)
+			continue
+		}
+		if err := queue.Remove(item.Path); err != nil {
+			log.Errorf("review of %s failed: %v", item.Path, err)
+		}
+	}
+	os.Exit(0)
+}
+
+// watchFlags are only known to watch, the renamer rejects them.
+var watchFlags = map[string]bool{"interval": true, "settle": true}
+
+// renamerArgs removes the flags that only watch knows from args,
+// along with their values.
+func renamerArgs(args []string) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		if strings.HasPrefix(args[i], "-") && watchFlags[strings.SplitN(name, "=", 2)[0]] {
+			if !strings.Contains(name, "=") {
+				i++
+			}
+			continue
+		}
+		result = append(result, args[i])
+	}
+	return result
+}
+
+// interactiveArgs removes the -non-interactive flag from args, and the
+// watch flags that items queued by older versions might still have.
+func interactiveArgs(args []string) []string {
+	var result []string
+	for _, a := range renamerArgs(args) {
+		if strings.HasPrefix(strings.TrimLeft(a, "-"), "non-interactive") {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+func manageCache(args []string) {
+	if len(args) == 0 || (args[0] == "forget" && len(args) != 2) || (args[0] != "forget" && len(args) != 1) {
+		usage()
+		os.Exit(1)
+	}
+
+	c, err := cache.Open(cache.Dir(), 0)
+	if err != nil {
+		log.Error(fmt.Sprintf("cache failed: %v", err))
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		for _, ch := range c.Choices() {
+			fmt.Printf("choice    %-5s %s (%d) -> %s (%d)\n", ch.Kind, ch.Query.Title, ch.Query.Year, ch.Result.Title, ch.Result.Year)
+		}
+		for _, r := range c.Responses() {
+			fmt.Printf("response  %-13s %s (%d), stored %s\n", r.Kind, r.Title, r.Year, r.Stored.Format(time.RFC3339))
+		}
+	case "clear":
+		err = c.Clear()
+	case "forget":
+		var n int
+		n, err = c.Forget(args[1])
+		log.Infof("Forgot %d entries for %s", n, args[1])
+	default:
+		usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("cache failed: %v", err))
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func newSearcher(arguments renamer.Parameters) search.Searcher {
+	tmdbClient, tvdbClient, c := newClients(arguments)
+	var s search.Searcher
+	if arguments.NonInteractive {
+		s = search.NewNonInteractiveSearcher(tmdbClient, tvdbClient, arguments.Threshold)
+	} else {
+		s = search.NewSearcher(tmdbClient, tvdbClient, prompt.NewPrompter())
+	}
+	s = search.UseTVProvider(s, arguments.TVProvider)
+	if c != nil {
+		s = search.RememberChoices(s, c)
+	}
+	return s
+}
+
+// newClients creates the metadata clients, backed by the
+// persistent cache unless it is disabled or unusable.
+func newClients(arguments renamer.Parameters) (tmdb.Client, tvdb.Client, *cache.Cache) {
+	tmdbClient := tmdb.NewClientWithLanguage(tmdb.BaseURL, config.GetToken("tmdb"), arguments.MetadataLanguage)
+	tvdbClient := tvdb.NewClient(tvdb.BaseURL, config.GetToken("tvdb"))
+	if arguments.NoCache {
+		return tmdbClient, tvdbClient, nil
+	}
+	c, err := cache.Open(cache.Dir(), arguments.CacheTTL)
+	if err != nil {
+		log.Warnf("Not using the cache: %v", err)
+		return tmdbClient, tvdbClient, nil
+	}
+	return c.WrapTMDB(tmdbClient, arguments.MetadataLanguage), c.WrapTVDB(tvdbClient), c
+}
+
+// reportUnresolved logs the files that were skipped by a
+// non-interactive search and tells whether there were any.
+func reportUnresolved(r *renamer.Renamer) bool {
+	unresolved := r.Unresolved()
+	if len(unresolved) == 0 {
+		return false
+	}
+	log.Warnf("%d file(s) unresolved, rerun them interactively:", len(unresolved))
+	for _, u := range unresolved {
+		log.Warn(u)
+	}
+	return true
+}
+
+// journaledFileSystem returns the file system to rename with,
+// unless it is a dry run all changes are journaled.
+func journaledFileSystem(dryRun bool) (fs.FileSystem, *journal.Journal) {
+	fileSystem := fs.NewFileSystem(dryRun)
+	if dryRun {
+		return fileSystem, nil
+	}
+	j := journal.New(journal.Dir())
+	return j.Wrap(fileSystem), j
+}
+
+func logJournal(j *journal.Journal) {
+	if j != nil && !j.Empty() {
+		log.Infof("Journal written to %s, undo with: plexname undo %s", j.Path(), j.RunID)
+	}
+}
+
+func undo(args []string) {
+	if len(args) > 1 {
+		usage()
+		os.Exit(1)
+	}
+	var runID string
+	if len(args) == 1 {
+		runID = args[0]
+	}
+
+	j, err := journal.Load(journal.Dir(), runID)
+	if err != nil {
+		log.Error(fmt.Sprintf("undo failed: %v", err))
+		os.Exit(1)
+	}
+	log.Infof("Undoing run %s", j.RunID)
+	if err := j.Undo(); err != nil {
+		log.Error(fmt.Sprintf("undo failed: %v", err))
+		os.Exit(1)
+	}
 
 	log.Info("Yay, done!")
 	os.Exit(0)
@@ -45,6 +403,13 @@ func usage() {
 	fmt.Println("Usage: ")
 	fmt.Println("  plexname [option]... source-dir [target-dir]")
 	fmt.Println("  plexname [option]... file")
+	fmt.Println("  plexname plan [-o plan.json] [option]... source-dir [target-dir]")
+	fmt.Println("  plexname apply plan.json")
+	fmt.Println("  plexname undo [run-id]")
+	fmt.Println("  plexname watch [-interval 10s] [-settle 1m] [option]... source-dir [target-dir]")
+	fmt.Println("  plexname review")
+	fmt.Println("  plexname cache list|clear|forget title")
+	fmt.Println("  plexname parse [-json] [-explain] [name]...")
 	fmt.Println("")
 	fmt.Println("Options:")
 	flag.PrintDefaults()
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/florianehmke/plexname/watch"
+)
+
+func TestQueuedArgs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plexname-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	watchArgs := []string{"-interval", "5s", "-non-interactive", "--settle=2m", "-mode", "copy", "-threshold=0.9"}
+	queue := watch.NewQueue(filepath.Join(dir, "queue.json"))
+	if err := queue.Add(watch.Item{Path: "/src/item", Args: renamerArgs(watchArgs)}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := queue.Items()
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one queued item, got %v (%v)", items, err)
+	}
+
+	expected := []string{"-mode", "copy", "-threshold=0.9"}
+	if args := interactiveArgs(items[0].Args); !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+	// Items queued before the watch flags were left out.
+	if args := interactiveArgs(watchArgs); !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
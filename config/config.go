@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Config is the content of the optional plexname config file.
+type Config struct {
+	// Templates maps a template kind (movie, episode, season) to its text.
+	Templates map[string]string `json:"templates"`
+
+	// Language of titles and other metadata, e.g. de-DE.
+	Language string `json:"language"`
+
+	// TVProvider is searched for series first, tvdb (default) or tmdb.
+	TVProvider string `json:"tv_provider"`
+}
+
+// Dir returns the plexname config directory, following the XDG base directory spec.
+func Dir() string {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "plexname")
+}
+
+// Path returns the path of the config file.
+func Path() string {
+	return filepath.Join(Dir(), "config.json")
+}
+
+// Load reads the config file. A missing file yields an empty config.
+func Load() (Config, error) {
+	return LoadFile(Path())
+}
+
+// LoadFile reads the config file at the given path. A missing file yields an empty config.
+func LoadFile(path string) (Config, error) {
+	var c Config
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("could not read config file %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("could not parse config file %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// DataDir returns the plexname data directory, following the XDG base directory spec.
+func DataDir() string {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, "plexname")
+}
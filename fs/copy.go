@@ -0,0 +1,99 @@
+package fs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+)
+
+func isCrossDevice(err error) bool {
+	if le, ok := err.(*os.LinkError); ok {
+		return le.Err == syscall.EXDEV
+	}
+	return false
+}
+
+// moveAcrossDevices copies oldpath next to newpath, verifies the copy and
+// only then renames it to newpath and deletes oldpath. Like a rename it
+// replaces an existing newpath.
+func moveAcrossDevices(oldpath, newpath string) error {
+	tmp := newpath + ".plexname-tmp"
+	if err := copyFile(oldpath, tmp); err != nil {
+		return err
+	}
+	if err := verifyCopy(oldpath, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, newpath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(oldpath)
+}
+
+// copyFile copies the content, permissions and modification time of oldpath to newpath.
+func copyFile(oldpath, newpath string) (err error) {
+	src, err := os.Open(oldpath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(newpath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := dst.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(newpath)
+		}
+	}()
+
+	if _, err = io.Copy(dst, src); err != nil {
+		return fmt.Errorf("copy failed: %v", err)
+	}
+	if err = dst.Sync(); err != nil {
+		return err
+	}
+	return os.Chtimes(newpath, info.ModTime(), info.ModTime())
+}
+
+func verifyCopy(oldpath, newpath string) error {
+	oldSum, err := checksum(oldpath)
+	if err != nil {
+		return err
+	}
+	newSum, err := checksum(newpath)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(oldSum, newSum) {
+		return fmt.Errorf("verification of %s failed: checksum mismatch", newpath)
+	}
+	return nil
+}
+
+func checksum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -2,10 +2,21 @@ package fs
 
 import (
 	"os"
+	"path/filepath"
 )
 
+// FileSystem makes the changes of a run, reading is done directly.
 type FileSystem interface {
+	// Rename moves oldpath to newpath, across devices if necessary.
 	Rename(oldpath, newpath string) error
+	// Copy copies oldpath to newpath.
+	Copy(oldpath, newpath string) error
+	// Link creates newpath as a hard link to oldpath.
+	Link(oldpath, newpath string) error
+	// Symlink creates newpath as a symbolic link to oldpath.
+	Symlink(oldpath, newpath string) error
+	// Reflink creates newpath as a copy-on-write clone of oldpath.
+	Reflink(oldpath, newpath string) error
 	MkdirAll(path string) error
 }
 
@@ -16,11 +27,51 @@ func NewFileSystem(noop bool) FileSystem {
 	return osFS{}
 }
 
+// Transfer oldpath to newpath using the given mode.
+func Transfer(fs FileSystem, mode Mode, oldpath, newpath string) error {
+	switch mode {
+	case ModeCopy:
+		return fs.Copy(oldpath, newpath)
+	case ModeHardlink:
+		return fs.Link(oldpath, newpath)
+	case ModeSymlink:
+		return fs.Symlink(oldpath, newpath)
+	case ModeReflink:
+		return fs.Reflink(oldpath, newpath)
+	default:
+		return fs.Rename(oldpath, newpath)
+	}
+}
+
 // osFS implements fileSystem using the local disk.
 type osFS struct{}
 
 func (osFS) Rename(oldpath, newpath string) error {
-	return os.Rename(oldpath, newpath)
+	err := os.Rename(oldpath, newpath)
+	if isCrossDevice(err) {
+		return moveAcrossDevices(oldpath, newpath)
+	}
+	return err
+}
+
+func (osFS) Copy(oldpath, newpath string) error {
+	return copyFile(oldpath, newpath)
+}
+
+func (osFS) Link(oldpath, newpath string) error {
+	return os.Link(oldpath, newpath)
+}
+
+func (osFS) Symlink(oldpath, newpath string) error {
+	abs, err := filepath.Abs(oldpath)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(abs, newpath)
+}
+
+func (osFS) Reflink(oldpath, newpath string) error {
+	return reflink(oldpath, newpath)
 }
 
 func (osFS) MkdirAll(path string) error {
@@ -33,6 +84,22 @@ func (noopFS) Rename(oldpath, newpath string) error {
 	return nil
 }
 
+func (noopFS) Copy(oldpath, newpath string) error {
+	return nil
+}
+
+func (noopFS) Link(oldpath, newpath string) error {
+	return nil
+}
+
+func (noopFS) Symlink(oldpath, newpath string) error {
+	return nil
+}
+
+func (noopFS) Reflink(oldpath, newpath string) error {
+	return nil
+}
+
 func (noopFS) MkdirAll(path string) error {
 	return nil
 }
@@ -0,0 +1,92 @@
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransfer(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "plexname-fs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, mode := range []Mode{ModeMove, ModeCopy, ModeHardlink, ModeSymlink} {
+		source := filepath.Join(tmp, mode.String()+"-source")
+		target := filepath.Join(tmp, mode.String()+"-target")
+		if err := ioutil.WriteFile(source, []byte(mode.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Transfer(osFS{}, mode, source, target); err != nil {
+			t.Errorf("%s failed: %v", mode, err)
+			continue
+		}
+
+		if data, err := ioutil.ReadFile(target); err != nil || string(data) != mode.String() {
+			t.Errorf("%s: expected target to have the source content", mode)
+		}
+		_, err := os.Stat(source)
+		if mode.KeepsSource() && err != nil {
+			t.Errorf("%s: expected source to be kept", mode)
+		}
+		if !mode.KeepsSource() && !os.IsNotExist(err) {
+			t.Errorf("%s: expected source to be gone", mode)
+		}
+	}
+}
+
+func TestMoveAcrossDevices(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "plexname-fs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	source := filepath.Join(tmp, "source")
+	target := filepath.Join(tmp, "target")
+	if err := ioutil.WriteFile(source, []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sourceInfo, _ := os.Stat(source)
+
+	if err := moveAcrossDevices(source, target); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(source); !os.IsNotExist(err) {
+		t.Error("expected source to be deleted")
+	}
+	targetInfo, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if targetInfo.Mode() != sourceInfo.Mode() || !targetInfo.ModTime().Equal(sourceInfo.ModTime()) {
+		t.Error("expected mode and modification time to be kept")
+	}
+
+	// An existing target is replaced, as by a rename on the same device.
+	if err := ioutil.WriteFile(source, []byte("better"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := moveAcrossDevices(source, target); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(target); err != nil || string(data) != "better" {
+		t.Errorf("expected the target to be replaced, got %q (%v)", data, err)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if m, err := ParseMode("hardlink"); err != nil || m != ModeHardlink {
+		t.Errorf("expected hardlink, got %s (%v)", m, err)
+	}
+	if m, err := ParseMode(""); err != nil || m != ModeMove {
+		t.Errorf("expected move as default, got %s (%v)", m, err)
+	}
+	if _, err := ParseMode("teleport"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,49 @@
+package fs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode is the way files are transferred to their new path.
+type Mode int
+
+// All known transfer modes.
+const (
+	ModeMove Mode = iota
+	ModeCopy
+	ModeHardlink
+	ModeSymlink
+	ModeReflink
+)
+
+var modeNames = map[Mode]string{
+	ModeMove:     "move",
+	ModeCopy:     "copy",
+	ModeHardlink: "hardlink",
+	ModeSymlink:  "symlink",
+	ModeReflink:  "reflink",
+}
+
+// ParseMode parses the given string to a transfer mode.
+func ParseMode(s string) (Mode, error) {
+	for m, name := range modeNames {
+		if name == strings.ToLower(s) {
+			return m, nil
+		}
+	}
+	if s == "" {
+		return ModeMove, nil
+	}
+	return ModeMove, fmt.Errorf("unknown mode: %s", s)
+}
+
+// String returns the string representation of m.
+func (m Mode) String() string {
+	return modeNames[m]
+}
+
+// KeepsSource reports whether the source still exists after a transfer with m.
+func (m Mode) KeepsSource() bool {
+	return m != ModeMove
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package fs
+
+import "errors"
+
+func reflink(oldpath, newpath string) error {
+	return errors.New("reflink is only supported on linux")
+}
@@ -0,0 +1,264 @@
+// Package journal records what a rename run did, so that it can be undone.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/florianehmke/plexname/config"
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/log"
+)
+
+// Journal of a single rename run.
+type Journal struct {
+	RunID   string    `json:"run_id"`
+	Started time.Time `json:"started"`
+
+	// Moves in the order they happened.
+	Moves []Move `json:"moves"`
+	// CreatedDirs in the order they were created (parents first).
+	CreatedDirs []string `json:"created_dirs"`
+
+	// Undone is set once all moves are undone.
+	Undone bool `json:"undone,omitempty"`
+
+	dir string
+}
+
+// Move of a single file, despite the name it might have been transferred
+// with any of the modes of the fs package.
+type Move struct {
+	Source  string    `json:"source"`
+	Target  string    `json:"target"`
+	Mode    fs.Mode   `json:"mode"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+
+	// Undone is set once the move is undone, it is skipped by another undo.
+	Undone bool `json:"undone,omitempty"`
+}
+
+// Dir returns the directory all journals are stored in.
+func Dir() string {
+	return filepath.Join(config.DataDir(), "journal")
+}
+
+// New creates a journal for a new run, stored in dir.
+// Nothing is written to disk until the first change is recorded.
+func New(dir string) *Journal {
+	now := time.Now()
+	id := now.Format("20060102-150405")
+	for i := 2; fileExists(filepath.Join(dir, id+".json")); i++ {
+		id = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), i)
+	}
+	return &Journal{
+		RunID:       id,
+		Started:     now,
+		Moves:       []Move{},
+		CreatedDirs: []string{},
+		dir:         dir,
+	}
+}
+
+// Load the journal of the given run from dir.
+// An empty run id loads the latest run that is not undone yet.
+func Load(dir, runID string) (*Journal, error) {
+	if runID != "" {
+		j, err := load(dir, runID)
+		if err != nil {
+			return nil, err
+		}
+		if j.Undone {
+			return nil, fmt.Errorf("run %s is already undone", runID)
+		}
+		return j, nil
+	}
+	runs, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		j, err := load(dir, runs[i])
+		if err != nil {
+			return nil, err
+		}
+		if !j.Undone {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("no journal to undo found in %s", dir)
+}
+
+func load(dir, runID string) (*Journal, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, runID+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read journal of run %s: %v", runID, err)
+	}
+	j := &Journal{dir: dir}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("could not parse journal of run %s: %v", runID, err)
+	}
+	return j, nil
+}
+
+// List the ids of all journaled runs in dir, oldest first.
+func List(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not list journals: %v", err)
+	}
+	var runs []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".json") {
+			runs = append(runs, strings.TrimSuffix(f.Name(), ".json"))
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		stampI, counterI := runOrder(runs[i])
+		stampJ, counterJ := runOrder(runs[j])
+		if stampI != stampJ {
+			return stampI < stampJ
+		}
+		return counterI < counterJ
+	})
+	return runs, nil
+}
+
+// runOrder splits a run id into its timestamp and counter, so that
+// -10 sorts after -2. The first run of a second has no counter.
+func runOrder(runID string) (string, int) {
+	if i := strings.LastIndex(runID, "-"); i > len("20060102") {
+		if n, err := strconv.Atoi(runID[i+1:]); err == nil {
+			return runID[:i], n
+		}
+	}
+	return runID, 1
+}
+
+// Path of the journal file.
+func (j *Journal) Path() string {
+	return filepath.Join(j.dir, j.RunID+".json")
+}
+
+// Empty reports whether nothing was recorded.
+func (j *Journal) Empty() bool {
+	return len(j.Moves) == 0 && len(j.CreatedDirs) == 0
+}
+
+// Wrap returns a file system that records every change made through it in j.
+func (j *Journal) Wrap(fileSystem fs.FileSystem) fs.FileSystem {
+	return &recorder{journal: j, fs: fileSystem}
+}
+
+func (j *Journal) save() error {
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return fmt.Errorf("could not create journal directory: %v", err)
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal of journal failed: %v", err)
+	}
+	tmp := j.Path() + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("could not write journal: %v", err)
+	}
+	return os.Rename(tmp, j.Path())
+}
+
+// recorder is a fs.FileSystem that journals all changes.
+type recorder struct {
+	journal *Journal
+	fs      fs.FileSystem
+}
+
+func (r *recorder) Rename(oldpath, newpath string) error {
+	return r.record(fs.ModeMove, oldpath, newpath, r.fs.Rename)
+}
+
+func (r *recorder) Copy(oldpath, newpath string) error {
+	return r.record(fs.ModeCopy, oldpath, newpath, r.fs.Copy)
+}
+
+func (r *recorder) Link(oldpath, newpath string) error {
+	return r.record(fs.ModeHardlink, oldpath, newpath, r.fs.Link)
+}
+
+func (r *recorder) Symlink(oldpath, newpath string) error {
+	return r.record(fs.ModeSymlink, oldpath, newpath, r.fs.Symlink)
+}
+
+func (r *recorder) Reflink(oldpath, newpath string) error {
+	return r.record(fs.ModeReflink, oldpath, newpath, r.fs.Reflink)
+}
+
+func (r *recorder) record(mode fs.Mode, oldpath, newpath string, transfer func(string, string) error) error {
+	source, err := filepath.Abs(oldpath)
+	if err != nil {
+		return err
+	}
+	target, err := filepath.Abs(newpath)
+	if err != nil {
+		return err
+	}
+	if err := transfer(oldpath, newpath); err != nil {
+		return err
+	}
+	move := Move{Source: source, Target: target, Mode: mode}
+	if info, err := os.Lstat(target); err == nil {
+		move.Size = info.Size()
+		move.ModTime = info.ModTime()
+	}
+	r.journal.Moves = append(r.journal.Moves, move)
+	// The file is moved already, failing now would leave the run half done.
+	if err := r.journal.save(); err != nil {
+		log.Warnf("Could not journal the move of %s: %v", oldpath, err)
+	}
+	return nil
+}
+
+func (r *recorder) MkdirAll(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	missing := missingDirs(abs)
+	if err := r.fs.MkdirAll(path); err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	r.journal.CreatedDirs = append(r.journal.CreatedDirs, missing...)
+	if err := r.journal.save(); err != nil {
+		log.Warnf("Could not journal the creation of %s: %v", path, err)
+	}
+	return nil
+}
+
+// missingDirs returns path and all its parents that do not exist yet, parents first.
+func missingDirs(path string) []string {
+	var missing []string
+	for p := filepath.Clean(path); !fileExists(p); p = filepath.Dir(p) {
+		missing = append([]string{p}, missing...)
+		if filepath.Dir(p) == p {
+			break
+		}
+	}
+	return missing
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
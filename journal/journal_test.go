@@ -0,0 +1,143 @@
+package journal_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/journal"
+)
+
+func TestUndo(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "plexname-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	source := filepath.Join(tmp, "downloads", "movie.mkv")
+	changed := filepath.Join(tmp, "downloads", "changed.mkv")
+	targetDir := filepath.Join(tmp, "movies", "Movie (1999)")
+	target := filepath.Join(targetDir, "Movie (1999).mkv")
+	changedTarget := filepath.Join(targetDir, "Changed (1999).mkv")
+
+	mustWrite(t, source, "movie")
+	mustWrite(t, changed, "changed")
+
+	j := journal.New(filepath.Join(tmp, "journal"))
+	fileSystem := j.Wrap(fs.NewFileSystem(false))
+	if err := fileSystem.MkdirAll(targetDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := fileSystem.Rename(source, target); err != nil {
+		t.Fatal(err)
+	}
+	if err := fileSystem.Rename(changed, changedTarget); err != nil {
+		t.Fatal(err)
+	}
+	if len(j.CreatedDirs) != 2 {
+		t.Errorf("expected 2 created directories, got %v", j.CreatedDirs)
+	}
+
+	mustWrite(t, changedTarget, "changed after the run")
+
+	loaded, err := journal.Load(filepath.Join(tmp, "journal"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.RunID != j.RunID {
+		t.Errorf("expected latest run %s, got %s", j.RunID, loaded.RunID)
+	}
+	if err := loaded.Undo(); err == nil {
+		t.Error("expected an error for the changed file")
+	}
+
+	if _, err := os.Stat(source); err != nil {
+		t.Errorf("expected %s to be restored", source)
+	}
+	if _, err := os.Stat(changedTarget); err != nil {
+		t.Errorf("expected %s to be left alone", changedTarget)
+	}
+	if _, err := os.Stat(targetDir); err != nil {
+		t.Errorf("expected non-empty %s to be kept", targetDir)
+	}
+
+	os.Remove(changedTarget)
+	if err := loaded.Undo(); err == nil {
+		t.Error("expected an error for the missing file")
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "movies")); !os.IsNotExist(err) {
+		t.Error("expected created directories to be removed")
+	}
+}
+
+func TestUndo_MarksRun(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "plexname-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "journal")
+
+	first := journal.New(dir)
+	mustWrite(t, filepath.Join(tmp, "a.mkv"), "a")
+	if err := first.Wrap(fs.NewFileSystem(false)).Rename(filepath.Join(tmp, "a.mkv"), filepath.Join(tmp, "A.mkv")); err != nil {
+		t.Fatal(err)
+	}
+	second := journal.New(dir)
+	mustWrite(t, filepath.Join(tmp, "b.mkv"), "b")
+	if err := second.Wrap(fs.NewFileSystem(false)).Rename(filepath.Join(tmp, "b.mkv"), filepath.Join(tmp, "B.mkv")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{second.RunID, first.RunID} {
+		j, err := journal.Load(dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j.RunID != expected {
+			t.Errorf("expected run %s, got %s", expected, j.RunID)
+		}
+		if err := j.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := journal.Load(dir, ""); err == nil {
+		t.Error("expected no run left to undo")
+	}
+	if _, err := journal.Load(dir, first.RunID); err == nil {
+		t.Error("expected an error for a run that is undone")
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestList(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "plexname-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, run := range []string{"20210102-100000-10", "20210102-100000", "20210101-235959", "20210102-100000-2"} {
+		mustWrite(t, filepath.Join(tmp, run+".json"), "{}")
+	}
+	runs, err := journal.List(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"20210101-235959", "20210102-100000", "20210102-100000-2", "20210102-100000-10"}
+	if !reflect.DeepEqual(runs, expected) {
+		t.Errorf("expected %v, got %v", expected, runs)
+	}
+}
@@ -0,0 +1,94 @@
+package journal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/log"
+)
+
+// Undo replays the moves of j in reverse and removes the directories
+// the run created if they are empty afterwards.
+//
+// A move is refused if its target changed since the run or if
+// something else already exists at its source. Undone moves are marked
+// in the journal, once all are undone the run is marked as undone.
+func (j *Journal) Undo() error {
+	var failed int
+	for i := len(j.Moves) - 1; i >= 0; i-- {
+		m := j.Moves[i]
+		if m.Undone {
+			continue
+		}
+		if err := undoMove(m); err != nil {
+			log.Warnf("Not restoring %s: %v", m.Source, err)
+			failed++
+			continue
+		}
+		j.Moves[i].Undone = true
+		log.Info(fmt.Sprintf("Restored:\nSource: %s\nTarget: %s", m.Target, m.Source))
+	}
+
+	for i := len(j.CreatedDirs) - 1; i >= 0; i-- {
+		dir := j.CreatedDirs[i]
+		if empty, err := isEmptyDir(dir); err != nil || !empty {
+			continue
+		}
+		if err := os.Remove(dir); err != nil {
+			log.Warnf("Could not remove %s: %v", dir, err)
+			continue
+		}
+		log.Infof("Removed directory: %s", dir)
+	}
+
+	j.Undone = failed == 0
+	if err := j.save(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d moves could not be undone", failed, len(j.Moves))
+	}
+	return nil
+}
+
+func undoMove(m Move) error {
+	info, err := os.Lstat(m.Target)
+	if err != nil {
+		return fmt.Errorf("target is gone: %v", err)
+	}
+	if info.Size() != m.Size || !info.ModTime().Equal(m.ModTime) {
+		return fmt.Errorf("%s changed since the run", m.Target)
+	}
+
+	if m.Mode.KeepsSource() {
+		// The target is a copy or a link, removing it is enough. Unless
+		// the source vanished, then the target is the only copy left.
+		if m.Mode != fs.ModeSymlink && !fileExists(m.Source) {
+			return fmt.Errorf("%s is gone, keeping its copy", m.Source)
+		}
+		return os.Remove(m.Target)
+	}
+
+	if fileExists(m.Source) {
+		return fmt.Errorf("%s already exists", m.Source)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.Source), os.ModePerm); err != nil {
+		return fmt.Errorf("mkdir failed: %v", err)
+	}
+	return fs.NewFileSystem(false).Rename(m.Target, m.Source)
+}
+
+func isEmptyDir(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.Readdirnames(1); err == io.EOF {
+		return true, nil
+	}
+	return false, nil
+}
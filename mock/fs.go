@@ -5,6 +5,7 @@ import "github.com/florianehmke/plexname/fs"
 type RenameFn func(oldPath string, newPath string) error
 type MkdirAllFn func(path string) error
 
+// NewMockFS creates a mocked file system, all transfer modes call renameFn.
 func NewMockFS(renameFn RenameFn, mkdirAllFn MkdirAllFn) fs.FileSystem {
 	return mockFS{
 		renameFn:   renameFn,
@@ -21,6 +22,22 @@ func (fs mockFS) Rename(oldpath, newpath string) error {
 	return fs.renameFn(oldpath, newpath)
 }
 
+func (fs mockFS) Copy(oldpath, newpath string) error {
+	return fs.renameFn(oldpath, newpath)
+}
+
+func (fs mockFS) Link(oldpath, newpath string) error {
+	return fs.renameFn(oldpath, newpath)
+}
+
+func (fs mockFS) Symlink(oldpath, newpath string) error {
+	return fs.renameFn(oldpath, newpath)
+}
+
+func (fs mockFS) Reflink(oldpath, newpath string) error {
+	return fs.renameFn(oldpath, newpath)
+}
+
 func (fs mockFS) MkdirAll(path string) error {
 	return fs.mkdirAllFn(path)
 }
@@ -1,16 +1,85 @@
 package mock
 
-import "github.com/florianehmke/plexname/tmdb"
+import (
+	"fmt"
+
+	"github.com/florianehmke/plexname/tmdb"
+)
 
 type tmdbClient struct {
 	response tmdb.SearchResponse
+	titles   map[int][]tmdb.AlternativeTitle
+	tv       tmdb.TVSearchResponse
+	seasons  []tmdb.Season
 	err      error
 }
 
 func NewMockTMDB(response tmdb.SearchResponse, err error) tmdb.Client {
-	return &tmdbClient{response, err}
+	return &tmdbClient{response: response, err: err}
+}
+
+// NewMockTMDBWithTV creates a mocked TMDB that knows TV shows, all
+// of which have the given seasons. It errors for movies if err is set.
+func NewMockTMDBWithTV(tv tmdb.TVSearchResponse, seasons []tmdb.Season, err error) tmdb.Client {
+	return &tmdbClient{tv: tv, seasons: seasons, err: err}
+}
+
+// NewMockTMDBWithTitles creates a mocked TMDB that knows
+// the alternative titles of movies, keyed by their ids.
+func NewMockTMDBWithTitles(response tmdb.SearchResponse, titles map[int][]tmdb.AlternativeTitle, err error) tmdb.Client {
+	return &tmdbClient{response: response, titles: titles, err: err}
 }
 
 func (c *tmdbClient) Search(query string, year int, page int) (*tmdb.SearchResponse, error) {
 	return &c.response, c.err
 }
+
+// Movie returns the result of the response with the given id.
+func (c *tmdbClient) Movie(id int) (*tmdb.Movie, error) {
+	for _, r := range c.response.Results {
+		if r.ID == id {
+			return &tmdb.Movie{SearchResult: r}, c.err
+		}
+	}
+	return nil, fmt.Errorf("movie %d not found", id)
+}
+
+// FindByIMDbID returns all results of the response and the tv response.
+func (c *tmdbClient) FindByIMDbID(imdbID string) (*tmdb.FindResponse, error) {
+	return &tmdb.FindResponse{MovieResults: c.response.Results, TVResults: c.tv.Results}, c.err
+}
+
+func (c *tmdbClient) AlternativeTitles(id int) (*tmdb.AlternativeTitlesResponse, error) {
+	return &tmdb.AlternativeTitlesResponse{ID: id, Titles: c.titles[id]}, c.err
+}
+
+func (c *tmdbClient) Translations(id int) (*tmdb.TranslationsResponse, error) {
+	return &tmdb.TranslationsResponse{ID: id}, c.err
+}
+
+func (c *tmdbClient) SearchTV(query string, year int, page int) (*tmdb.TVSearchResponse, error) {
+	return &c.tv, c.err
+}
+
+// TV returns the result of the tv response with the given id.
+func (c *tmdbClient) TV(id int) (*tmdb.TV, error) {
+	for _, r := range c.tv.Results {
+		if r.ID == id {
+			tv := &tmdb.TV{TVSearchResult: r}
+			for _, s := range c.seasons {
+				tv.Seasons = append(tv.Seasons, tmdb.SeasonSummary{SeasonNumber: s.SeasonNumber, EpisodeCount: len(s.Episodes)})
+			}
+			return tv, c.err
+		}
+	}
+	return nil, fmt.Errorf("tv show %d not found", id)
+}
+
+func (c *tmdbClient) Season(tvID int, season int) (*tmdb.Season, error) {
+	for _, s := range c.seasons {
+		if s.SeasonNumber == season {
+			return &s, c.err
+		}
+	}
+	return nil, fmt.Errorf("season %d of tv show %d not found", season, tvID)
+}
@@ -1,16 +1,44 @@
 package mock
 
-import "github.com/florianehmke/plexname/tvdb"
+import (
+	"fmt"
+
+	"github.com/florianehmke/plexname/tvdb"
+)
 
 type tvdbClient struct {
 	response tvdb.SearchResponse
+	episodes []tvdb.Episode
 	err      error
 }
 
 func NewMockTVDB(response tvdb.SearchResponse, err error) tvdb.Client {
-	return &tvdbClient{response, err}
+	return &tvdbClient{response: response, err: err}
+}
+
+func NewMockTVDBWithEpisodes(response tvdb.SearchResponse, episodes []tvdb.Episode, err error) tvdb.Client {
+	return &tvdbClient{response: response, episodes: episodes, err: err}
 }
 
 func (c *tvdbClient) Search(query string) (*tvdb.SearchResponse, error) {
 	return &c.response, c.err
 }
+
+func (c *tvdbClient) Episodes(seriesID int) ([]tvdb.Episode, error) {
+	return c.episodes, c.err
+}
+
+// SearchByIMDbID returns the whole response.
+func (c *tvdbClient) SearchByIMDbID(imdbID string) (*tvdb.SearchResponse, error) {
+	return &c.response, c.err
+}
+
+// Series returns the result of the response with the given id.
+func (c *tvdbClient) Series(id int) (*tvdb.Series, error) {
+	for _, r := range c.response.Results {
+		if r.ID == id {
+			return &tvdb.Series{SearchResult: r}, c.err
+		}
+	}
+	return nil, fmt.Errorf("series %d not found", id)
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AudioChannels is the internal representation of a channel layout such as 5.1.
+type AudioChannels int
+
+// All known channel layouts.
+const (
+	ChannelsNA AudioChannels = iota
+	Mono
+	Stereo
+	Surround51
+	Surround61
+	Surround71
+)
+
+// Channel layouts mapped to their string representations.
+var (
+	audioChannelsNames = map[AudioChannels]string{
+		ChannelsNA: "--",
+		Mono:       "1.0",
+		Stereo:     "2.0",
+		Surround51: "5.1",
+		Surround61: "6.1",
+		Surround71: "7.1",
+	}
+
+	audioChannelsMap = map[string]AudioChannels{
+		"1.0":    Mono,
+		"mono":   Mono,
+		"2.0":    Stereo,
+		"stereo": Stereo,
+		"5.1":    Surround51,
+		"6.1":    Surround61,
+		"7.1":    Surround71,
+	}
+
+	// audioChannelsRegEx matches layouts like DD5.1, DTS 7.1 or AAC2.0,
+	// but neither the 5.1 of S05.1080p nor that of a date.
+	audioChannelsRegEx = regexp.MustCompile(`(?:^|[^0-9])([12567])[ ._]([01])(?:ch)?(?:[^0-9]|$)`)
+)
+
+// ParseAudioChannels parses the given string to a channel layout.
+func ParseAudioChannels(channels string) (AudioChannels, error) {
+	if c, ok := audioChannelsMap[strings.ToLower(channels)]; ok {
+		return c, nil
+	}
+	if channels == "" {
+		return ChannelsNA, nil
+	}
+	return ChannelsNA, fmt.Errorf("unknown audio channels: %s", channels)
+}
+
+// String returns the string representation of c.
+func (c AudioChannels) String() string {
+	return audioChannelsNames[c]
+}
+
+// AudioChannelsNames returns a map containing all string representations.
+func AudioChannelsNames() map[AudioChannels]string {
+	return audioChannelsNames
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AudioCodec is the internal representation of an audio codec such as DTS.
+type AudioCodec int
+
+// All known audio codecs.
+const (
+	AudioCodecNA AudioCodec = iota
+	AAC
+	AC3
+	EAC3
+	DTS
+	DTSHD
+	DTSHDMA
+	DTSX
+	TrueHD
+	TrueHDAtmos
+	FLAC
+	MP3
+	Opus
+)
+
+// Audio codecs mapped to their string representations.
+var (
+	audioCodecNames = map[AudioCodec]string{
+		AudioCodecNA: "--",
+		AAC:          "AAC",
+		AC3:          "AC3",
+		EAC3:         "EAC3",
+		DTS:          "DTS",
+		DTSHD:        "DTS-HD",
+		DTSHDMA:      "DTS-HD MA",
+		DTSX:         "DTS-X",
+		TrueHD:       "TrueHD",
+		TrueHDAtmos:  "TrueHD Atmos",
+		FLAC:         "FLAC",
+		MP3:          "MP3",
+		Opus:         "Opus",
+	}
+
+	audioCodecMap = map[string]AudioCodec{
+		"aac":          AAC,
+		"ac3":          AC3,
+		"dd":           AC3,
+		"eac3":         EAC3,
+		"ddp":          EAC3,
+		"dts":          DTS,
+		"dts-hd":       DTSHD,
+		"dtshd":        DTSHD,
+		"dts-hd ma":    DTSHDMA,
+		"dtshdma":      DTSHDMA,
+		"dts-x":        DTSX,
+		"dtsx":         DTSX,
+		"truehd":       TrueHD,
+		"truehd atmos": TrueHDAtmos,
+		"atmos":        TrueHDAtmos,
+		"flac":         FLAC,
+		"mp3":          MP3,
+		"opus":         Opus,
+	}
+
+	// audioCodecRegExList is tried in order, the first match wins.
+	audioCodecRegExList = []struct {
+		codec AudioCodec
+		rxp   *regexp.Regexp
+	}{
+		{TrueHDAtmos, regexp.MustCompile(`truehd[ ._-]?(?:\d[ ._]\d[ ._-]?)?atmos`)},
+		{TrueHD, regexp.MustCompile(`(?:^|[^a-z0-9])truehd`)},
+		{DTSX, regexp.MustCompile(`(?:^|[^a-z0-9])dts[ ._:-]?x(?:[^a-z0-9]|$)`)},
+		{DTSHDMA, regexp.MustCompile(`(?:^|[^a-z0-9])dts[ ._-]?hd[ ._-]?ma(?:[^a-z]|$)`)},
+		{DTSHD, regexp.MustCompile(`(?:^|[^a-z0-9])dts[ ._-]?hd(?:[^a-z]|$)`)},
+		{DTS, regexp.MustCompile(`(?:^|[^a-z0-9])dts(?:[^a-z]|$)`)},
+		{EAC3, regexp.MustCompile(`(?:^|[^a-z0-9])(?:e-?ac-?3|ddp|dd\+)(?:[^a-z]|$)`)},
+		{AC3, regexp.MustCompile(`(?:^|[^a-z0-9])(?:ac-?3|dd)(?:[^a-z]|$)`)},
+		{AAC, regexp.MustCompile(`(?:^|[^a-z0-9])aac(?:[^a-z]|$)`)},
+		{FLAC, regexp.MustCompile(`(?:^|[^a-z0-9])flac(?:[^a-z]|$)`)},
+		{MP3, regexp.MustCompile(`(?:^|[^a-z0-9])mp3(?:[^a-z0-9]|$)`)},
+		{Opus, regexp.MustCompile(`(?:^|[^a-z0-9])opus(?:[^a-z]|$)`)},
+	}
+)
+
+// ParseAudioCodec parses the given string to an audio codec.
+func ParseAudioCodec(codec string) (AudioCodec, error) {
+	if c, ok := audioCodecMap[strings.ToLower(codec)]; ok {
+		return c, nil
+	}
+	if codec == "" {
+		return AudioCodecNA, nil
+	}
+	return AudioCodecNA, fmt.Errorf("unknown audio codec: %s", codec)
+}
+
+// String returns the string representation of c.
+func (c AudioCodec) String() string {
+	return audioCodecNames[c]
+}
+
+// AudioCodecNames returns a map containing all string representations.
+func AudioCodecNames() map[AudioCodec]string {
+	return audioCodecNames
+}
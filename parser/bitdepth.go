@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BitDepth is the internal representation of the color depth of a video.
+type BitDepth int
+
+// All known bit depths.
+const (
+	BitDepthNA BitDepth = iota
+	Bit8
+	Bit10
+	Bit12
+)
+
+// Bit depths mapped to their string representations.
+var (
+	bitDepthNames = map[BitDepth]string{
+		BitDepthNA: "--",
+		Bit8:       "8bit",
+		Bit10:      "10bit",
+		Bit12:      "12bit",
+	}
+
+	bitDepthMap = map[string]BitDepth{
+		"8":     Bit8,
+		"8bit":  Bit8,
+		"10":    Bit10,
+		"10bit": Bit10,
+		"hi10p": Bit10,
+		"12":    Bit12,
+		"12bit": Bit12,
+	}
+
+	// bitDepthRegEx matches 10bit, 10-bit, 10 bit and Hi10P.
+	bitDepthRegEx = regexp.MustCompile(`(?:^|[^a-z0-9])(?:(8|10|12)[ ._-]?bits?|hi(10)p)(?:[^a-z0-9]|$)`)
+)
+
+// ParseBitDepth parses the given string to a bit depth.
+func ParseBitDepth(depth string) (BitDepth, error) {
+	if d, ok := bitDepthMap[strings.ToLower(depth)]; ok {
+		return d, nil
+	}
+	if depth == "" {
+		return BitDepthNA, nil
+	}
+	return BitDepthNA, fmt.Errorf("unknown bit depth: %s", depth)
+}
+
+// String returns the string representation of d.
+func (d BitDepth) String() string {
+	return bitDepthNames[d]
+}
+
+// BitDepthNames returns a map containing all string representations.
+func BitDepthNames() map[BitDepth]string {
+	return bitDepthNames
+}
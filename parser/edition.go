@@ -0,0 +1,22 @@
+package parser
+
+import "regexp"
+
+// editionRegExList maps edition markers to the edition names used
+// in plex file names. It is tried in order, the first match wins.
+var editionRegExList = []struct {
+	edition string
+	rxp     *regexp.Regexp
+}{
+	{"Director's Cut", regexp.MustCompile(`(?:^|[^a-z0-9])directors?'?s?[ ._-]?cut(?:[^a-z0-9]|$)`)},
+	{"Extended", regexp.MustCompile(`(?:^|[^a-z0-9])extended(?:[ ._-]?(?:cut|edition|version))?(?:[^a-z0-9]|$)`)},
+	{"Final Cut", regexp.MustCompile(`(?:^|[^a-z0-9])final[ ._-]?cut(?:[^a-z0-9]|$)`)},
+	{"Theatrical", regexp.MustCompile(`(?:^|[^a-z0-9])theatrical(?:[ ._-]?(?:cut|edition|version))?(?:[^a-z0-9]|$)`)},
+	{"Unrated", regexp.MustCompile(`(?:^|[^a-z0-9])unrated(?:[^a-z0-9]|$)`)},
+	{"Uncut", regexp.MustCompile(`(?:^|[^a-z0-9])uncut(?:[^a-z0-9]|$)`)},
+	{"IMAX", regexp.MustCompile(`(?:^|[^a-z0-9])imax(?:[^a-z0-9]|$)`)},
+	{"Ultimate Edition", regexp.MustCompile(`(?:^|[^a-z0-9])ultimate[ ._-]?(?:cut|edition)(?:[^a-z0-9]|$)`)},
+	{"Special Edition", regexp.MustCompile(`(?:^|[^a-z0-9])special[ ._-]?edition(?:[^a-z0-9]|$)`)},
+	{"Remastered", regexp.MustCompile(`(?:^|[^a-z0-9])remastered(?:[^a-z0-9]|$)`)},
+	{"Criterion", regexp.MustCompile(`(?:^|[^a-z0-9])criterion(?:[ ._-]?collection)?(?:[^a-z0-9]|$)`)},
+}
@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Confidence tells how sure the parser is about the value of a field.
+type Confidence int
+
+// All confidence levels.
+const (
+	ConfidenceNA Confidence = iota
+	ConfidenceLow
+	ConfidenceMedium
+	ConfidenceHigh
+)
+
+// Confidence levels mapped to their string representations.
+var confidenceNames = map[Confidence]string{
+	ConfidenceNA:     "--",
+	ConfidenceLow:    "low",
+	ConfidenceMedium: "medium",
+	ConfidenceHigh:   "high",
+}
+
+// String returns the string representation of c.
+func (c Confidence) String() string {
+	return confidenceNames[c]
+}
+
+// MarshalText returns the string representation of c.
+func (c Confidence) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// Provenance tells where the value of a field came from.
+type Provenance struct {
+	Field string `json:"field"` // name of the field in Result, e.g. Season
+	Value string `json:"value"` // the value of the field, e.g. 2
+
+	Token      string     `json:"token"` // the part of the name that decided it, e.g. s02e07
+	Rule       string     `json:"rule"`  // the rule that matched, e.g. episode pattern
+	Confidence Confidence `json:"confidence"`
+}
+
+// Explanation holds the provenance of all fields of a parse result.
+type Explanation struct {
+	fields map[string]Provenance
+}
+
+func newExplanation() *Explanation {
+	return &Explanation{fields: map[string]Provenance{}}
+}
+
+// Of returns the provenance of the given field.
+func (e *Explanation) Of(field string) (Provenance, bool) {
+	if e == nil {
+		return Provenance{}, false
+	}
+	p, ok := e.fields[field]
+	return p, ok
+}
+
+// All returns the provenance of all fields that are set,
+// in the order of their declaration in Result.
+func (e *Explanation) All() []Provenance {
+	if e == nil {
+		return nil
+	}
+	var all []Provenance
+	t := reflect.TypeOf(Result{})
+	for i := 0; i < t.NumField(); i++ {
+		if p, ok := e.fields[t.Field(i).Name]; ok {
+			all = append(all, p)
+		}
+	}
+	return all
+}
+
+func (e *Explanation) set(field, token, rule string, c Confidence) {
+	e.fields[field] = Provenance{
+		Field:      field,
+		Token:      strings.Trim(token, " ._-[]()/"),
+		Rule:       rule,
+		Confidence: c,
+	}
+}
+
+func (e *Explanation) forget(field string) {
+	delete(e.fields, field)
+}
+
+// finish records the values of r, fields that are set without
+// a known provenance are added, fields that are unset are dropped.
+func (e *Explanation) finish(r Result) {
+	v := reflect.ValueOf(r)
+	for name, value := range setFields(r) {
+		p, ok := e.fields[name]
+		if !ok {
+			p = Provenance{Field: name, Rule: "unknown"}
+		}
+		p.Value = value
+		e.fields[name] = p
+	}
+	for name := range e.fields {
+		if f := v.FieldByName(name); !f.IsValid() || isZero(f) {
+			delete(e.fields, name)
+		}
+	}
+}
+
+// setFields returns the string representations of
+// all fields of r that are set, keyed by their names.
+func setFields(r Result) map[string]string {
+	fields := map[string]string{}
+	v := reflect.ValueOf(r)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+		if isZero(f) {
+			continue
+		}
+		if d, ok := f.Interface().(time.Time); ok {
+			fields[t.Field(i).Name] = d.Format("2006-01-02")
+			continue
+		}
+		fields[t.Field(i).Name] = fmt.Sprint(f.Interface())
+	}
+	return fields
+}
+
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
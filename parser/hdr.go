@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HDR is the internal representation of a high dynamic range format such as HDR10.
+type HDR int
+
+// All known HDR formats.
+const (
+	HDRNA HDR = iota
+	HDRGeneric
+	HDR10
+	HDR10Plus
+	DolbyVision
+	HLG
+)
+
+// HDR formats mapped to their string representations.
+var (
+	hdrNames = map[HDR]string{
+		HDRNA:       "--",
+		HDRGeneric:  "HDR",
+		HDR10:       "HDR10",
+		HDR10Plus:   "HDR10+",
+		DolbyVision: "DV",
+		HLG:         "HLG",
+	}
+
+	hdrMap = map[string]HDR{
+		"hdr":          HDRGeneric,
+		"hdr10":        HDR10,
+		"hdr10+":       HDR10Plus,
+		"hdr10plus":    HDR10Plus,
+		"dv":           DolbyVision,
+		"dovi":         DolbyVision,
+		"dolby vision": DolbyVision,
+		"hlg":          HLG,
+	}
+
+	// hdrRegExList is tried in order, the first match wins.
+	// Dolby Vision comes first, releases often carry an HDR10 fallback.
+	hdrRegExList = []struct {
+		hdr HDR
+		rxp *regexp.Regexp
+	}{
+		{DolbyVision, regexp.MustCompile(`(?:^|[^a-z0-9])(?:dv|dovi|dolby[ ._-]?vision)(?:[^a-z0-9]|$)`)},
+		{HDR10Plus, regexp.MustCompile(`(?:^|[^a-z0-9])hdr10(?:\+|plus|p)`)},
+		{HDR10, regexp.MustCompile(`(?:^|[^a-z0-9])hdr10(?:[^a-z0-9]|$)`)},
+		{HLG, regexp.MustCompile(`(?:^|[^a-z0-9])hlg(?:[^a-z0-9]|$)`)},
+		{HDRGeneric, regexp.MustCompile(`(?:^|[^a-z0-9])hdr(?:[^a-z0-9]|$)`)},
+	}
+)
+
+// ParseHDR parses the given string to an HDR format.
+func ParseHDR(hdr string) (HDR, error) {
+	if h, ok := hdrMap[strings.ToLower(hdr)]; ok {
+		return h, nil
+	}
+	if hdr == "" {
+		return HDRNA, nil
+	}
+	return HDRNA, fmt.Errorf("unknown hdr format: %s", hdr)
+}
+
+// String returns the string representation of h.
+func (h HDR) String() string {
+	return hdrNames[h]
+}
+
+// HDRNames returns a map containing all string representations.
+func HDRNames() map[HDR]string {
+	return hdrNames
+}
@@ -97,6 +97,85 @@ var (
 	}
 )
 
+// ISO 639-1 codes of all languages, as used for subtitle file names.
+var langCodes = map[Language]string{
+	English:    "en",
+	French:     "fr",
+	Spanish:    "es",
+	German:     "de",
+	Italian:    "it",
+	Danish:     "da",
+	Dutch:      "nl",
+	Japanese:   "ja",
+	Cantonese:  "zh",
+	Mandarin:   "zh",
+	Russian:    "ru",
+	Polish:     "pl",
+	Vietnamese: "vi",
+	Swedish:    "sv",
+	Norwegian:  "no",
+	Finnish:    "fi",
+	Turkish:    "tr",
+	Portuguese: "pt",
+	Flemish:    "nl",
+	Greek:      "el",
+	Korean:     "ko",
+	Hungarian:  "hu",
+}
+
+// ISO 639-2 codes (bibliographic and terminologic) mapped to languages.
+var langCodes3 = map[string]Language{
+	"eng": English,
+	"fre": French,
+	"fra": French,
+	"spa": Spanish,
+	"ger": German,
+	"deu": German,
+	"ita": Italian,
+	"dan": Danish,
+	"dut": Dutch,
+	"nld": Dutch,
+	"jpn": Japanese,
+	"yue": Cantonese,
+	"chi": Mandarin,
+	"zho": Mandarin,
+	"rus": Russian,
+	"pol": Polish,
+	"vie": Vietnamese,
+	"swe": Swedish,
+	"nor": Norwegian,
+	"fin": Finnish,
+	"tur": Turkish,
+	"por": Portuguese,
+	"gre": Greek,
+	"ell": Greek,
+	"kor": Korean,
+	"hun": Hungarian,
+}
+
+// ParseLanguageCode parses an ISO 639-1 or 639-2 code or a
+// language name, as found in subtitle file names.
+func ParseLanguageCode(code string) (Language, bool) {
+	code = strings.ToLower(code)
+	if l, ok := langCodes3[code]; ok {
+		return l, true
+	}
+	for l, c := range langCodes {
+		if c == code && l != Cantonese && l != Flemish {
+			return l, true
+		}
+	}
+	if l, ok := langMap[code]; ok {
+		return l, true
+	}
+	return LangNA, false
+}
+
+// Code returns the ISO 639-1 code of l, or an empty string if l is not known.
+func (l Language) Code() string {
+	return langCodes[l]
+}
+
 // ParseLanguage parses the given string to a language.
 func ParseLanguage(lang string) (Language, error) {
 	if l, ok := langMap[strings.ToLower(lang)]; ok {
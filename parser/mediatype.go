@@ -13,6 +13,12 @@ const (
 )
 
 var (
+	mediaTypeNames = map[MediaType]string{
+		MediaTypeUnknown: "--",
+		MediaTypeMovie:   "movie",
+		MediaTypeTV:      "tv",
+	}
+
 	mediaTypes = map[string]MediaType{
 		"tv":     MediaTypeTV,
 		"series": MediaTypeTV,
@@ -32,3 +38,8 @@ func ParseMediaType(s string) (MediaType, error) {
 	}
 	return MediaTypeUnknown, fmt.Errorf("unknown media type: %s", s)
 }
+
+// String returns the string representation of mt.
+func (mt MediaType) String() string {
+	return mediaTypeNames[mt]
+}
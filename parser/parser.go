@@ -4,16 +4,29 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
 func Parse(s string, overrides Result) Result {
+	r, _ := Explain(s, overrides)
+	return r
+}
+
+// Explain parses s like Parse does and tells where
+// the value of each field of the result came from.
+func Explain(s string, overrides Result) (Result, *Explanation) {
 	p := parser{
-		parseData: newParseData(s),
-		overrides: overrides,
-		result:    Result{},
+		parseData:   newParseData(s),
+		overrides:   overrides,
+		result:      Result{},
+		explanation: newExplanation(),
 	}
 
+	p.parseReleaseGroup()
+	p.parseAirDate()
+	p.parseAbsoluteEpisode()
+	p.parseEdition()
 	p.parseTitle()
 	p.parseYear()
 	p.parseResolution()
@@ -22,6 +35,12 @@ func Parse(s string, overrides Result) Result {
 	p.parseDualLanguage()
 	p.parseRemux()
 	p.parseProper()
+	p.parseVideoCodec()
+	p.parseAudioCodec()
+	p.parseAudioChannels()
+	p.parseHDR()
+	p.parseBitDepth()
+	p.parseSceneGroup()
 	p.parseSeasonAndEpisode()
 	p.parseSpecial()
 
@@ -29,31 +48,57 @@ func Parse(s string, overrides Result) Result {
 	p.setMediaType()
 
 	p.result.mergeIn(overrides)
-	return p.result
+	p.explainOverrides(overrides)
+	p.explanation.finish(p.result)
+	return p.result, p.explanation
+}
+
+// explain records the provenance of the value just set for field.
+func (p *parser) explain(field, token, rule string, c Confidence) {
+	p.explanation.set(field, token, rule, c)
+}
+
+// explained tells whether the provenance of field is known.
+func (p *parser) explained(field string) bool {
+	_, ok := p.explanation.Of(field)
+	return ok
+}
+
+// explainOverrides marks the fields that are set by the overrides.
+func (p *parser) explainOverrides(overrides Result) {
+	for field, value := range setFields(overrides) {
+		p.explain(field, value, "override", ConfidenceHigh)
+	}
 }
 
 type parser struct {
 	parseData parseData
 
-	overrides Result
-	result    Result
+	overrides   Result
+	result      Result
+	explanation *Explanation
 }
 
 type parseData struct {
-	toParse string
-	length  int
+	original string
+	toParse  string
+	length   int
 
 	joined string
 	tokens []string
+
+	// airDate is the position of an air date in toParse, if any.
+	airDate []int
 }
 
 func newParseData(s string) parseData {
 	ls := strings.ToLower(s)
 	return parseData{
-		toParse: ls,
-		length:  len(ls),
-		joined:  clean(ls),
-		tokens:  tokenize(ls),
+		original: s,
+		toParse:  ls,
+		length:   len(ls),
+		joined:   clean(ls),
+		tokens:   tokenize(ls),
 	}
 }
 
@@ -78,22 +123,232 @@ func (p *parser) parseMediaType() {
 }
 
 func (p *parser) parseTitle() {
+	if p.result.Title != "" {
+		return // set by an anime style name
+	}
+	toParse := p.parseData.toParse
+	// A title without anything after it might as well be something else.
+	rule, confidence := "whole name", ConfidenceLow
+	if p.parseData.airDate != nil {
+		toParse = toParse[:p.parseData.airDate[0]]
+		rule, confidence = "text before air date", ConfidenceMedium
+	}
+	tokens := titleTokens(toParse)
+	episodeWords := map[string]bool{"season": true, "episode": true}
+	if !p.hasMovieSignal() {
+		episodeWords["part"] = true
+	}
 	var titleTokens []string
-	for _, t := range p.parseData.tokens {
-		if yearRegEx.full.MatchString(t) || singleEpisode.matchFull(t) || dualEpisode.matchFull(t) {
+	for i, t := range tokens {
+		if yearRegEx.full.MatchString(t) || episodeTokenRegEx.MatchString(t) {
+			rule, confidence = "text before year or episode", ConfidenceMedium
+			break
+		}
+		// Season 2 Episode 7, the word is followed by a number.
+		if episodeWords[t] && i+1 < len(tokens) && isNumber(tokens[i+1]) {
+			rule, confidence = "text before year or episode", ConfidenceMedium
 			break
 		}
 		titleTokens = append(titleTokens, t)
 	}
-	p.result.Title = strings.Join(titleTokens, " ")
+	p.result.Title = strings.Join(strings.Fields(strings.Join(joinAcronyms(titleTokens), " ")), " ")
+	p.explain("Title", p.result.Title, rule, confidence)
 }
 
-func (p *parser) parseYear() {
+// parseReleaseGroup finds a release group in leading brackets,
+// e.g. [Group] Show - 01.mkv. The file name wins over its folder.
+func (p *parser) parseReleaseGroup() {
+	matches := releaseGroupRegEx.FindAllStringSubmatch(p.parseData.original, -1)
+	if len(matches) > 0 {
+		p.result.ReleaseGroup = strings.TrimSpace(matches[len(matches)-1][1])
+		p.explain("ReleaseGroup", matches[len(matches)-1][0], "leading brackets", ConfidenceHigh)
+	}
+}
+
+// parseSceneGroup finds the release group at the end of scene names,
+// e.g. Movie.1999.1080p.BluRay.x264-GROUP. The file name wins over its
+// folder. Without any quality marker a name like Spider-Man has no group.
+func (p *parser) parseSceneGroup() {
+	if p.result.ReleaseGroup != "" {
+		return
+	}
+	if p.result.Resolution == ResNA && p.result.Source == SourceNA &&
+		p.result.VideoCodec == VideoCodecNA && p.result.AudioCodec == AudioCodecNA {
+		return
+	}
+	segments := strings.Split(p.parseData.original, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		m := sceneGroupRegEx.FindStringSubmatch(segments[i])
+		if m == nil || isNumber(m[1]) || isKnownTag(strings.ToLower(m[1])) || episodeTokenRegEx.MatchString(strings.ToLower(m[1])) {
+			continue
+		}
+		p.result.ReleaseGroup = m[1]
+		p.explain("ReleaseGroup", m[1], "scene group suffix", ConfidenceMedium)
+		return
+	}
+}
+
+// parseEdition finds edition markers such as Director's Cut or IMAX.
+// Only markers after the title count, The Final Cut or Unrated are titles.
+func (p *parser) parseEdition() {
+	start := p.releaseInfoStart()
+	if start < 0 {
+		return
+	}
+	toParse := p.parseData.toParse[start:]
+	for _, e := range editionRegExList {
+		if loc := e.rxp.FindStringIndex(toParse); loc != nil {
+			p.result.Edition = e.edition
+			p.explain("Edition", toParse[loc[0]:loc[1]], "edition marker after title", ConfidenceHigh)
+			return
+		}
+	}
+}
+
+// releaseInfoStart returns the position in toParse of the year, the
+// resolution or the first quality token, whatever comes first, or -1.
+// A year at the very start is taken as part of the title, e.g. 2012.
+func (p *parser) releaseInfoStart() int {
+	toParse := p.parseData.toParse
+	offset := 0
+	for i, t := range p.parseData.tokens {
+		if t == "" {
+			continue
+		}
+		pos := offset + strings.Index(toParse[offset:], t)
+		offset = pos + len(t)
+		if (i > 0 && yearRegEx.full.MatchString(t)) || isQualityTag(t) {
+			return pos
+		}
+	}
+	return -1
+}
+
+// parseAbsoluteEpisode finds the episode number of anime style names
+// like [Group] Show - 137 [1080p].mkv, which come without a season.
+// The title of such names is taken from the text before the number.
+func (p *parser) parseAbsoluteEpisode() {
+	if !p.result.AirDate.IsZero() {
+		return
+	}
+	for _, t := range p.parseData.tokens {
+		if episodeTokenRegEx.MatchString(t) && !seasonRegEx.full.MatchString(t) {
+			return
+		}
+	}
+
+	segment := p.parseData.toParse[strings.LastIndex(p.parseData.toParse, "/")+1:]
+	for _, rxp := range absoluteRegExList {
+		match := rxp.FindStringSubmatch(segment)
+		if match == nil {
+			continue
+		}
+		params := map[string]string{}
+		for i, name := range rxp.SubexpNames() {
+			params[name] = match[i]
+		}
+		if yearRegEx.full.MatchString(params["absolute"]) {
+			continue
+		}
+		// Without a [group] in front, a dash and a number are too common
+		// after the release info, e.g. Movie 1999 1080p - 01.mkv.
+		if !strings.HasPrefix(segment, "[") && hasReleaseInfo(titleTokens(params["title"])) {
+			continue
+		}
+		absolute, err := strconv.Atoi(params["absolute"])
+		if err != nil || absolute == 0 {
+			continue
+		}
+		p.result.AbsoluteEpisode = absolute
+		title := joinAcronyms(titleTokens(params["title"]))
+		p.result.Title = strings.Join(strings.Fields(strings.Join(title, " ")), " ")
+		p.explain("AbsoluteEpisode", params["absolute"], "absolute episode number", ConfidenceMedium)
+		p.explain("Title", p.result.Title, "text before absolute episode", ConfidenceMedium)
+		return
+	}
+}
+
+// hasReleaseInfo tells if tokens hold a year or a quality tag,
+// a year at the very start is taken as part of the title.
+func hasReleaseInfo(tokens []string) bool {
+	for i, t := range tokens {
+		if (i > 0 && yearRegEx.full.MatchString(t)) || isQualityTag(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAirDate finds the air date of daily shows, e.g. Show.2021.03.15.Guest.
+func (p *parser) parseAirDate() {
+	for _, rxp := range airDateRegExList {
+		for _, loc := range rxp.FindAllStringSubmatchIndex(p.parseData.toParse, -1) {
+			params := map[string]int{}
+			for i, name := range rxp.SubexpNames() {
+				if name != "" {
+					params[name], _ = strconv.Atoi(p.parseData.toParse[loc[2*i]:loc[2*i+1]])
+				}
+			}
+			// Both separators have to be the same, 2021.03-15 is no date.
+			if p.parseData.toParse[loc[4]:loc[5]] != p.parseData.toParse[loc[8]:loc[9]] {
+				continue
+			}
+			date := time.Date(params["year"], time.Month(params["month"]), params["day"], 0, 0, 0, 0, time.UTC)
+			if date.Year() != params["year"] || int(date.Month()) != params["month"] || date.Day() != params["day"] {
+				continue
+			}
+			p.result.AirDate = date
+			// The date spans from the first to the last of its five groups.
+			p.parseData.airDate = []int{loc[2], loc[11]}
+			p.explain("AirDate", p.parseData.toParse[loc[2]:loc[11]], "air date", ConfidenceHigh)
+			return
+		}
+	}
+}
+
+func (p *parser) hasYear() bool {
 	for _, t := range p.parseData.tokens {
+		if yearRegEx.full.MatchString(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMovieSignal tells whether the name hints at a movie, by a year, an
+// edition, a Blu-ray or DVD source or a remux.
+func (p *parser) hasMovieSignal() bool {
+	if p.hasYear() || p.result.Year != 0 || p.result.Edition != "" {
+		return true
+	}
+	for _, t := range p.parseData.tokens {
+		if s, ok := srcMap[t]; ok && (s == BluRay || s == DVD) {
+			return true
+		}
+		if t == "remux" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseYear() {
+	tokens := p.parseData.tokens
+	if d := p.parseData.airDate; d != nil {
+		// The year of an air date is not the year of the show.
+		tokens = tokenize(p.parseData.toParse[:d[0]] + ";" + p.parseData.toParse[d[1]:])
+	}
+	// Of several years the last one wins, e.g. 2012.2009.1080p.
+	rule, confidence := "year token", ConfidenceHigh
+	for _, t := range tokens {
 		if yearRegEx.full.MatchString(t) {
 			year, err := strconv.Atoi(t)
 			if err == nil {
+				if p.result.Year != 0 {
+					rule, confidence = "last of several years", ConfidenceMedium
+				}
 				p.result.Year = year
+				p.explain("Year", t, rule, confidence)
 			}
 		}
 	}
@@ -103,10 +358,14 @@ func (p *parser) parseResolution() {
 	for _, t := range p.parseData.tokens {
 		if res, ok := resMap[t]; ok {
 			p.result.Resolution = res
+			p.explain("Resolution", t, "resolution token", ConfidenceHigh)
 		}
 	}
 	for k, res := range resMap {
 		if strings.Contains(p.parseData.joined, k) {
+			if p.result.Resolution != res || !p.explained("Resolution") {
+				p.explain("Resolution", k, "resolution inside a word", ConfidenceLow)
+			}
 			p.result.Resolution = res
 		}
 	}
@@ -116,6 +375,7 @@ func (p *parser) parseSource() {
 	for _, t := range p.parseData.tokens {
 		if src, ok := srcMap[t]; ok {
 			p.result.Source = src
+			p.explain("Source", t, "source token", ConfidenceHigh)
 		}
 	}
 	for k, src := range srcMap {
@@ -125,6 +385,9 @@ func (p *parser) parseSource() {
 			continue
 		}
 		if strings.Contains(p.parseData.joined, k) {
+			if p.result.Source != src || !p.explained("Source") {
+				p.explain("Source", k, "source inside a word", ConfidenceMedium)
+			}
 			p.result.Source = src
 		}
 	}
@@ -134,6 +397,12 @@ func (p *parser) parseLanguage() {
 	for _, t := range p.parseData.tokens {
 		if lang, ok := langMap[t]; ok {
 			p.result.Language = lang
+			// Short codes such as fr or it are common words too.
+			if len(t) < 3 {
+				p.explain("Language", t, "language code token", ConfidenceMedium)
+			} else {
+				p.explain("Language", t, "language token", ConfidenceHigh)
+			}
 		}
 	}
 	for k, lang := range langMap {
@@ -141,6 +410,9 @@ func (p *parser) parseLanguage() {
 			continue
 		}
 		if strings.Contains(p.parseData.joined, k) {
+			if p.result.Language != lang || !p.explained("Language") {
+				p.explain("Language", k, "language inside a word", ConfidenceLow)
+			}
 			p.result.Language = lang
 		}
 	}
@@ -153,6 +425,7 @@ func (p *parser) parseDualLanguage() {
 			webDLCount := strings.Count(p.parseData.joined, "webdl")
 			if dlCount > webDLCount {
 				p.result.DualLanguage = True
+				p.explain("DualLanguage", t, "dl token", ConfidenceMedium)
 			}
 		}
 	}
@@ -162,10 +435,12 @@ func (p *parser) parseRemux() {
 	for _, t := range p.parseData.tokens {
 		if t == "remux" {
 			p.result.Remux = True
+			p.explain("Remux", t, "remux token", ConfidenceHigh)
 		}
 	}
-	if strings.Contains(p.parseData.joined, "remux") {
+	if strings.Contains(p.parseData.joined, "remux") && p.result.Remux != True {
 		p.result.Remux = True
+		p.explain("Remux", "remux", "remux inside a word", ConfidenceMedium)
 	}
 }
 
@@ -179,40 +454,114 @@ func (p *parser) parseProper() {
 	for _, t := range p.parseData.tokens {
 		if _, ok := propers[t]; ok {
 			p.result.Proper = True
+			p.explain("Proper", t, "proper token", ConfidenceHigh)
 		}
 	}
 	for k := range propers {
-		if strings.Contains(p.parseData.joined, k) {
+		if strings.Contains(p.parseData.joined, k) && p.result.Proper != True {
 			p.result.Proper = True
+			p.explain("Proper", k, "proper inside a word", ConfidenceMedium)
+		}
+	}
+}
+
+func (p *parser) parseVideoCodec() {
+	for _, c := range videoCodecRegExList {
+		if m := c.rxp.FindString(p.parseData.toParse); m != "" {
+			p.result.VideoCodec = c.codec
+			p.explain("VideoCodec", m, "video codec pattern", ConfidenceHigh)
+			return
 		}
 	}
 }
 
+func (p *parser) parseAudioCodec() {
+	for _, c := range audioCodecRegExList {
+		if m := c.rxp.FindString(p.parseData.toParse); m != "" {
+			p.result.AudioCodec = c.codec
+			p.explain("AudioCodec", m, "audio codec pattern", ConfidenceHigh)
+			return
+		}
+	}
+}
+
+func (p *parser) parseAudioChannels() {
+	if m := audioChannelsRegEx.FindStringSubmatch(p.parseData.toParse); m != nil {
+		p.result.AudioChannels = audioChannelsMap[m[1]+"."+m[2]]
+		p.explain("AudioChannels", m[0], "channel layout", ConfidenceHigh)
+	}
+}
+
+func (p *parser) parseHDR() {
+	for _, h := range hdrRegExList {
+		if m := h.rxp.FindString(p.parseData.toParse); m != "" {
+			p.result.HDR = h.hdr
+			p.explain("HDR", m, "hdr pattern", ConfidenceHigh)
+			return
+		}
+	}
+}
+
+func (p *parser) parseBitDepth() {
+	if m := bitDepthRegEx.FindStringSubmatch(p.parseData.toParse); m != nil {
+		p.result.BitDepth = bitDepthMap[m[1]+m[2]]
+		p.explain("BitDepth", m[0], "bit depth", ConfidenceHigh)
+	}
+}
+
 func (p *parser) parseSeasonAndEpisode() {
 	for _, t := range p.parseData.tokens {
-		var r Result
-		if dualEpisode.episode.sub.MatchString(t) {
-			r = populateResultFromRxpList([]*regexp.Regexp{
-				dualEpisode.episode.full,
-				dualEpisode.complete.full,
-			}, t)
-		} else {
-			r = populateResultFromRxpList([]*regexp.Regexp{
-				singleEpisode.episode.full,
-				singleEpisode.season.full,
-				singleEpisode.complete.full,
-			}, t)
-		}
-		p.result.mergeIn(r)
-	}
-	if p.result.Episode1 == 0 || p.result.Season == 0 {
+		if seasonRegEx.full.MatchString(t) {
+			p.result.mergeIn(getResultFromRegEx(seasonRegEx.full, t))
+			p.explain("Season", t, "season token", ConfidenceHigh)
+		}
+	}
+	r, token := getLastResultFromRxpList([]*regexp.Regexp{seasonWordRegEx}, p.parseData.toParse)
+	p.mergeInExplained(r, token, "season word", ConfidenceHigh)
+	r, token = getLastResultFromRxpList(episodeRegExList, p.parseData.toParse)
+	p.mergeInExplained(r, token, "episode pattern", ConfidenceHigh)
+	if p.result.Episode == 0 || p.result.Season == 0 {
 		r := getBestResultFromRxpList(tvAlternativeRegExList, p.parseData.toParse)
 		if r.score() > 0 {
 			p.result.Season = r.Season
-			p.result.Episode1 = r.Episode1
-			p.result.Episode2 = r.Episode2
+			p.result.Episode = r.Episode
+			p.result.LastEpisode = r.LastEpisode
+			p.explain("Season", strconv.Itoa(r.Season), "season of the folder", ConfidenceLow)
+			p.explain("Episode", strconv.Itoa(r.Episode), "number at the start of the file", ConfidenceLow)
 		}
 	}
+	// Movies have parts too, e.g. Deathly Hallows Part 1, they come with a
+	// year or another movie signal.
+	if p.result.Episode == 0 && p.result.Season == 0 && p.result.AbsoluteEpisode == 0 && !p.hasMovieSignal() {
+		if r, token := getLastResultFromRxpList([]*regexp.Regexp{partRegEx}, p.parseData.toParse); r.Episode > 0 {
+			p.result.Season = 1
+			p.result.Episode = r.Episode
+			p.explain("Season", token, "part of a mini series", ConfidenceLow)
+			p.explain("Episode", token, "part of a mini series", ConfidenceLow)
+		}
+	}
+	// With a season, e.g. [Group] Show S2 - 05, the number is relative to it.
+	if p.result.AbsoluteEpisode > 0 && p.result.Season > 0 {
+		if p.result.Episode == 0 {
+			p.result.Episode = p.result.AbsoluteEpisode
+			p.explain("Episode", strconv.Itoa(p.result.AbsoluteEpisode), "episode number after a season", ConfidenceMedium)
+		}
+		p.result.AbsoluteEpisode = 0
+		p.explanation.forget("AbsoluteEpisode")
+	}
+}
+
+// mergeInExplained merges r into the result and records token as the
+// provenance of its season and episode fields.
+func (p *parser) mergeInExplained(r Result, token, rule string, c Confidence) {
+	p.result.mergeIn(r)
+	if r.Season != 0 {
+		p.explain("Season", token, rule, c)
+	}
+	if r.Episode != 0 {
+		p.explain("Episode", token, rule, c)
+		p.explain("LastEpisode", token, rule, c)
+	}
 }
 
 func (p *parser) parseSpecial() {
@@ -226,10 +575,12 @@ func (p *parser) parseSpecial() {
 	for _, t := range p.parseData.tokens {
 		if _, ok := specials[t]; ok {
 			p.result.Special = True
+			p.explain("Special", t, "special token", ConfidenceHigh)
 		}
 	}
-	if strings.Contains(p.parseData.joined, "s00") {
+	if strings.Contains(p.parseData.joined, "s00") && p.result.Special != True {
 		p.result.Special = True
+		p.explain("Special", "s00", "season zero", ConfidenceMedium)
 	}
 }
 
@@ -237,14 +588,24 @@ func (p *parser) doPlausibilityCheck() {
 	// If a season (>0) is present, it can't be a special.
 	if p.result.Season > 0 && p.result.Special == True {
 		p.result.Special = False
+		p.explain("Special", "", "contradicted by the season", ConfidenceMedium)
 	}
 }
 
 func (p *parser) setMediaType() {
-	if (p.result.Episode1 > 0 && p.result.Season > 0) || p.result.Special == True {
-		p.result.MediaType = MediaTypeTV
-	} else {
+	p.result.MediaType = MediaTypeTV
+	switch {
+	case p.result.Episode > 0 && p.result.Season > 0:
+		p.explain("MediaType", "", "has season and episode", ConfidenceHigh)
+	case p.result.AbsoluteEpisode > 0:
+		p.explain("MediaType", "", "has absolute episode", ConfidenceMedium)
+	case !p.result.AirDate.IsZero():
+		p.explain("MediaType", "", "has air date", ConfidenceHigh)
+	case p.result.Special == True:
+		p.explain("MediaType", "", "is special", ConfidenceMedium)
+	default:
 		p.result.MediaType = MediaTypeMovie
+		p.explain("MediaType", "", "default without episode", ConfidenceLow)
 	}
 }
 
@@ -255,6 +616,57 @@ func isValidFileNameCharacter(r rune) bool {
 	return false
 }
 
+// isKnownTag tells whether s is a quality tag, these end scene names too,
+// e.g. Movie.WEB-DL or Movie.2019-1080p.
+// isQualityTag tells whether s is a resolution, source, codec or HDR tag.
+func isQualityTag(s string) bool {
+	if _, ok := resMap[s]; ok {
+		return true
+	}
+	if _, ok := srcMap[s]; ok {
+		return true
+	}
+	if _, ok := videoCodecMap[s]; ok {
+		return true
+	}
+	if _, ok := audioCodecMap[s]; ok {
+		return true
+	}
+	_, ok := hdrMap[s]
+	return ok
+}
+
+func isKnownTag(s string) bool {
+	if _, ok := resMap[s]; ok {
+		return true
+	}
+	if _, ok := srcMap[s]; ok {
+		return true
+	}
+	if _, ok := langMap[s]; ok {
+		return true
+	}
+	if _, ok := videoCodecMap[s]; ok {
+		return true
+	}
+	if _, ok := audioCodecMap[s]; ok {
+		return true
+	}
+	if _, ok := hdrMap[s]; ok {
+		return true
+	}
+	switch s {
+	case "dl", "rip", "hd", "ma", "x", "sample", "cut":
+		return true
+	}
+	return false
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
 func clean(s string) string {
 	return strings.Map(
 		func(r rune) rune {
@@ -2,6 +2,7 @@ package parser_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/florianehmke/plexname/parser"
 )
@@ -78,11 +79,135 @@ var parserTests = []parserTest{
 	},
 	{
 		toParse:      "Some.Title.S04E01",
-		expectations: parser.Result{Season: 4, Episode1: 1, Title: "some title"},
+		expectations: parser.Result{Season: 4, Episode: 1, Title: "some title"},
 	},
 	{
 		toParse:      "Some.Title.S01E02",
-		expectations: parser.Result{Season: 1, Episode1: 2},
+		expectations: parser.Result{Season: 1, Episode: 2},
+	},
+	{
+		toParse:      "Some.Title.S01E01-E03.720p",
+		expectations: parser.Result{Season: 1, Episode: 1, LastEpisode: 3, Resolution: parser.R720},
+	},
+	{
+		toParse:      "Some.Title.S01E01-03.720p",
+		expectations: parser.Result{Season: 1, Episode: 1, LastEpisode: 3, Resolution: parser.R720},
+	},
+	{
+		toParse:      "Some.Title.S01E01E02E03",
+		expectations: parser.Result{Season: 1, Episode: 1, LastEpisode: 3, Title: "some title"},
+	},
+	{
+		toParse:      "Some Title 1x01-03",
+		expectations: parser.Result{Season: 1, Episode: 1, LastEpisode: 3, Title: "some title"},
+	},
+	{
+		toParse:      "Some.Title.S01E05-1080p",
+		expectations: parser.Result{Season: 1, Episode: 5, Resolution: parser.R1080},
+	},
+	{
+		toParse:      "Some.Title.1x05.HDTV",
+		expectations: parser.Result{Season: 1, Episode: 5, Source: parser.HDTV, Title: "some title"},
+	},
+	{
+		toParse:      "Some.Title.01x05",
+		expectations: parser.Result{Season: 1, Episode: 5, Title: "some title"},
+	},
+	{
+		toParse:      "Some Title Season 2 Episode 7",
+		expectations: parser.Result{Season: 2, Episode: 7, Title: "some title"},
+	},
+	{
+		toParse:      "Some.Title.Season.2/Episode.7.mkv",
+		expectations: parser.Result{Season: 2, Episode: 7, Title: "some title"},
+	},
+	{
+		toParse:      "Some.Title.Part.3.720p",
+		expectations: parser.Result{Season: 1, Episode: 3, Resolution: parser.R720, Title: "some title"},
+	},
+	{
+		toParse:      "Some.Title.Part.1.2010.1080p",
+		expectations: parser.Result{Year: 2010, Resolution: parser.R1080, Title: "some title part 1"},
+	},
+	{
+		toParse:      "Movie.Part.2.1080p.BluRay.x264-GRP.mkv",
+		expectations: parser.Result{Resolution: parser.R1080, Source: parser.BluRay, VideoCodec: parser.H264, ReleaseGroup: "GRP"},
+	},
+	{
+		toParse: "Some.Title.2019.2160p.UHD.BluRay.x265.10bit.HDR10.DTS-HD.MA.5.1",
+		expectations: parser.Result{
+			Year:          2019,
+			Resolution:    parser.R2160,
+			Source:        parser.BluRay,
+			VideoCodec:    parser.H265,
+			BitDepth:      parser.Bit10,
+			HDR:           parser.HDR10,
+			AudioCodec:    parser.DTSHDMA,
+			AudioChannels: parser.Surround51,
+		},
+	},
+	{
+		toParse: "Some.Title.2019.2160p.WEB-DL.DDP5.1.Atmos.DV.HEVC",
+		expectations: parser.Result{
+			Year:          2019,
+			Resolution:    parser.R2160,
+			Source:        parser.WEBDL,
+			VideoCodec:    parser.H265,
+			HDR:           parser.DolbyVision,
+			AudioCodec:    parser.EAC3,
+			AudioChannels: parser.Surround51,
+		},
+	},
+	{
+		toParse: "Some.Title.2019.1080p.BluRay.TrueHD.7.1.Atmos.AVC.Remux",
+		expectations: parser.Result{
+			Year:          2019,
+			Resolution:    parser.R1080,
+			Source:        parser.BluRay,
+			Remux:         parser.True,
+			VideoCodec:    parser.H264,
+			AudioCodec:    parser.TrueHDAtmos,
+			AudioChannels: parser.Surround71,
+		},
+	},
+	{
+		toParse: "Some.Title.S05.1080p.HDTV.DD5.1.x264",
+		expectations: parser.Result{
+			Season:        5,
+			Resolution:    parser.R1080,
+			Source:        parser.HDTV,
+			VideoCodec:    parser.H264,
+			AudioCodec:    parser.AC3,
+			AudioChannels: parser.Surround51,
+		},
+	},
+	{
+		toParse:      "Some.Title.S05E01.720p.HDR.AAC2.0.Hi10P",
+		expectations: parser.Result{Season: 5, Episode: 1, Resolution: parser.R720, HDR: parser.HDRGeneric, AudioCodec: parser.AAC, AudioChannels: parser.Stereo, BitDepth: parser.Bit10},
+	},
+	{
+		toParse:      "Some.Title.1999.Directors.Cut.1080p.BluRay.x264-GRP.mkv",
+		expectations: parser.Result{Title: "some title", Year: 1999, Edition: "Director's Cut", Resolution: parser.R1080, Source: parser.BluRay, VideoCodec: parser.H264, ReleaseGroup: "GRP"},
+	},
+	{
+		toParse:      "Some.Title.2019.Extended.Edition.720p.WEB-DL",
+		expectations: parser.Result{Title: "some title", Year: 2019, Edition: "Extended", Resolution: parser.R720, Source: parser.WEBDL},
+	},
+	{
+		toParse:      "Some.Title.720p.Extended.WEB-DL",
+		expectations: parser.Result{Edition: "Extended", Resolution: parser.R720, Source: parser.WEBDL},
+	},
+	{
+		toParse:      "The.Final.Cut.2004.1080p.BluRay.x264-GRP.mkv",
+		expectations: parser.Result{Title: "the final cut", Year: 2004, Resolution: parser.R1080, Source: parser.BluRay, VideoCodec: parser.H264, ReleaseGroup: "GRP"},
+	},
+	{
+		toParse:      "Unrated.2009.1080p.BluRay.x264-GRP.mkv",
+		expectations: parser.Result{Title: "unrated", Year: 2009, Resolution: parser.R1080, Source: parser.BluRay, VideoCodec: parser.H264, ReleaseGroup: "GRP"},
+	},
+	{
+		toParse:      "Some.Title.2019.IMAX.2160p/movie-file-GROUP.mkv",
+		expectations: parser.Result{Year: 2019, Edition: "IMAX", Resolution: parser.R2160, ReleaseGroup: "GROUP"},
 	},
 	{
 		toParse:      "Some.Title.WEB-DL",
@@ -102,7 +227,7 @@ var parserTests = []parserTest{
 	},
 	{
 		toParse:      "Some.Title.E01E02",
-		expectations: parser.Result{Episode1: 1, Episode2: 2},
+		expectations: parser.Result{Episode: 1, LastEpisode: 2},
 	},
 	{
 		toParse: "Some.Title.S04E01.GERMAN.DL.1080p.BluRay/the200-1080p.mkv",
@@ -112,7 +237,7 @@ var parserTests = []parserTest{
 			DualLanguage: parser.True,
 			Resolution:   parser.R1080,
 			Source:       parser.BluRay,
-			Episode1:     1,
+			Episode:      1,
 		},
 	},
 	{
@@ -121,9 +246,90 @@ var parserTests = []parserTest{
 			Season:     22,
 			Resolution: parser.R1080,
 			Source:     parser.WEBDL,
-			Episode1:   1,
+			Episode:    1,
+		},
+	},
+	{
+		toParse: "[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv",
+		expectations: parser.Result{
+			Title:           "one piece",
+			AbsoluteEpisode: 1071,
+			Resolution:      parser.R1080,
+			ReleaseGroup:    "SubsPlease",
+		},
+	},
+	{
+		toParse: "[Group] Show Title 137 [720p].mkv",
+		expectations: parser.Result{
+			Title:           "show title",
+			AbsoluteEpisode: 137,
+			Resolution:      parser.R720,
+			ReleaseGroup:    "Group",
+		},
+	},
+	{
+		toParse:      "Movie 1999 1080p - 01.mkv",
+		expectations: parser.Result{Year: 1999, Resolution: parser.R1080},
+	},
+	{
+		toParse:      "Movie.Title.720p.BluRay - 02.mkv",
+		expectations: parser.Result{Resolution: parser.R720, Source: parser.BluRay},
+	},
+	{
+		toParse:      "Show Title - 12.mkv",
+		expectations: parser.Result{Title: "show title", AbsoluteEpisode: 12},
+	},
+	{
+		toParse: "[Group] Show Title S2 - 05v2 [1080p].mkv",
+		expectations: parser.Result{
+			Season:       2,
+			Episode:      5,
+			Resolution:   parser.R1080,
+			ReleaseGroup: "Group",
+		},
+	},
+	{
+		toParse: "The.Daily.Show.2021.03.15.Guest.Name.720p.WEB.h264",
+		expectations: parser.Result{
+			Title:      "the daily show",
+			AirDate:    time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC),
+			Resolution: parser.R720,
+			Source:     parser.WEBRip,
+			VideoCodec: parser.H264,
 		},
 	},
+	{
+		toParse:      "Some Show 2021-03-15",
+		expectations: parser.Result{Title: "some show", AirDate: time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)},
+	},
+	{
+		toParse:      "Some Show - 15.03.2021 German",
+		expectations: parser.Result{Title: "some show", AirDate: time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), Language: parser.German},
+	},
+	{
+		toParse:      "Some.Title.2021.13.45.1080p",
+		expectations: parser.Result{Year: 2021, Resolution: parser.R1080},
+	},
+	{
+		toParse:      "Some Title - 2049 1080p",
+		expectations: parser.Result{Year: 2049, Resolution: parser.R1080},
+	},
+	{
+		toParse:      "Marvels.Agents.of.S.H.I.E.L.D.S01E01.720p",
+		expectations: parser.Result{Title: "marvels agents of shield", Season: 1, Episode: 1, Resolution: parser.R720},
+	},
+	{
+		toParse:      "Marvel's Agents of S.H.I.E.L.D. - S01E01",
+		expectations: parser.Result{Title: "marvels agents of shield", Season: 1, Episode: 1},
+	},
+	{
+		toParse:      "Fast & Furious 2009 1080p",
+		expectations: parser.Result{Title: "fast and furious", Year: 2009, Resolution: parser.R1080},
+	},
+	{
+		toParse:      "Amélie.2001.German.1080p",
+		expectations: parser.Result{Title: "amélie", Year: 2001, Language: parser.German, Resolution: parser.R1080},
+	},
 }
 
 func TestParse(t *testing.T) {
@@ -153,11 +359,11 @@ func compareResult(t *testing.T, expected parser.Result, got parser.Result) {
 	if expected.Season != got.Season {
 		t.Errorf("expected season=%d, got season=%d", expected.Season, got.Season)
 	}
-	if expected.Episode1 != got.Episode1 {
-		t.Errorf("expected episode1=%d, got episode1=%d", expected.Episode1, got.Episode1)
+	if expected.Episode != got.Episode {
+		t.Errorf("expected episode=%d, got episode=%d", expected.Episode, got.Episode)
 	}
-	if expected.Episode2 != got.Episode2 {
-		t.Errorf("expected episode2=%d, got episode2=%d", expected.Episode2, got.Episode2)
+	if expected.LastEpisode != got.LastEpisode {
+		t.Errorf("expected last-episode=%d, got last-episode=%d", expected.LastEpisode, got.LastEpisode)
 	}
 	if expected.Year != got.Year {
 		t.Errorf("expected year=%d, got year=%d", expected.Year, got.Year)
@@ -165,27 +371,165 @@ func compareResult(t *testing.T, expected parser.Result, got parser.Result) {
 	if expected.Title != "" && expected.Title != got.Title {
 		t.Errorf("expected title=%s, got title=%s", expected.Title, got.Title)
 	}
+	if !expected.AirDate.Equal(got.AirDate) {
+		t.Errorf("expected air-date=%s, got air-date=%s", expected.AirDate, got.AirDate)
+	}
+	if expected.Edition != got.Edition {
+		t.Errorf("expected edition=%s, got edition=%s", expected.Edition, got.Edition)
+	}
+	if expected.AbsoluteEpisode != got.AbsoluteEpisode {
+		t.Errorf("expected absolute-episode=%d, got absolute-episode=%d", expected.AbsoluteEpisode, got.AbsoluteEpisode)
+	}
+	if expected.ReleaseGroup != "" && expected.ReleaseGroup != got.ReleaseGroup {
+		t.Errorf("expected release-group=%s, got release-group=%s", expected.ReleaseGroup, got.ReleaseGroup)
+	}
+	if expected.VideoCodec != got.VideoCodec {
+		t.Errorf("expected video-codec=%s, got video-codec=%s", expected.VideoCodec, got.VideoCodec)
+	}
+	if expected.AudioCodec != got.AudioCodec {
+		t.Errorf("expected audio-codec=%s, got audio-codec=%s", expected.AudioCodec, got.AudioCodec)
+	}
+	if expected.AudioChannels != got.AudioChannels {
+		t.Errorf("expected audio-channels=%s, got audio-channels=%s", expected.AudioChannels, got.AudioChannels)
+	}
+	if expected.HDR != got.HDR {
+		t.Errorf("expected hdr=%s, got hdr=%s", expected.HDR, got.HDR)
+	}
+	if expected.BitDepth != got.BitDepth {
+		t.Errorf("expected bit-depth=%s, got bit-depth=%s", expected.BitDepth, got.BitDepth)
+	}
 	if expected.DualLanguage != got.DualLanguage {
 		t.Errorf("expected dual-language=%d, got dual-language=%d", expected.DualLanguage, got.DualLanguage)
 	}
 }
 
+func TestParse_IsTV(t *testing.T) {
+	for _, s := range []string{
+		"[Group] Show - 137 [1080p].mkv",
+		"The.Daily.Show.2021.03.15.Guest.720p.WEB.h264",
+		"Some.Title.1x05.HDTV",
+		"Some.Title.01x05",
+		"Some Title Season 2 Episode 7",
+		"Some.Title.Part.3.720p",
+	} {
+		if got := parser.Parse(s, parser.Result{}); !got.IsTV() {
+			t.Errorf("expected %s to be tv, got %d", s, got.MediaType)
+		}
+	}
+	if got := parser.Parse("Some.Title.Part.1.2010.1080p", parser.Result{}); !got.IsMovie() {
+		t.Errorf("expected a part with a year to be a movie, got %d", got.MediaType)
+	}
+	if got := parser.Parse("Movie.Part.2.1080p.BluRay.x264-GRP.mkv", parser.Result{}); !got.IsMovie() {
+		t.Errorf("expected a part of a blu-ray release to be a movie, got %d", got.MediaType)
+	}
+}
+
+func TestParse_ReleaseGroup(t *testing.T) {
+	for s, group := range map[string]string{
+		"Some.Title.1999.1080p.BluRay.x264-GRP.mkv":  "GRP",
+		"Some.Title.S01E01-E03.720p":                 "",
+		"Some.Title.WEB-DL":                          "",
+		"Spider-Man.mkv":                             "",
+		"[SubsPlease] Show - 01 (1080p) [HASH].mkv":  "SubsPlease",
+		"Some.Title.1999.1080p.BluRay-GROUP/abc.mkv": "GROUP",
+	} {
+		if got := parser.Parse(s, parser.Result{}); got.ReleaseGroup != group {
+			t.Errorf("%s: expected release group '%s', got '%s'", s, group, got.ReleaseGroup)
+		}
+	}
+}
+
 func TestOverride(t *testing.T) {
 	overrides := parser.Result{
 		Title:        "Some Title",
 		MediaType:    parser.MediaTypeTV,
 		Year:         1999,
 		Season:       2,
-		Episode1:     15,
+		Episode:      15,
 		Resolution:   parser.R2160,
 		Source:       parser.BluRay,
 		Language:     parser.German,
 		Remux:        parser.True,
 		Proper:       parser.False,
 		DualLanguage: parser.True,
+
+		VideoCodec:    parser.H265,
+		AudioCodec:    parser.DTSHDMA,
+		AudioChannels: parser.Surround51,
+		HDR:           parser.HDR10,
+		BitDepth:      parser.Bit10,
 	}
 	result := parser.Parse("1080p web dl of Some Title", overrides)
 	if overrides != result {
 		t.Errorf("expected overrides to have an effect")
 	}
 }
+
+func TestExplain(t *testing.T) {
+	_, e := parser.Explain("Show.S02E07.Germany.720p.WEB.h264-GRP.mkv", parser.Result{Year: 2010})
+	expected := map[string]parser.Provenance{
+		"Title":        {Field: "Title", Value: "show", Token: "show", Rule: "text before year or episode", Confidence: parser.ConfidenceMedium},
+		"MediaType":    {Field: "MediaType", Value: "tv", Rule: "has season and episode", Confidence: parser.ConfidenceHigh},
+		"Year":         {Field: "Year", Value: "2010", Token: "2010", Rule: "override", Confidence: parser.ConfidenceHigh},
+		"Season":       {Field: "Season", Value: "2", Token: "s02e07", Rule: "episode pattern", Confidence: parser.ConfidenceHigh},
+		"Episode":      {Field: "Episode", Value: "7", Token: "s02e07", Rule: "episode pattern", Confidence: parser.ConfidenceHigh},
+		"Language":     {Field: "Language", Value: "German", Token: "german", Rule: "language inside a word", Confidence: parser.ConfidenceLow},
+		"Resolution":   {Field: "Resolution", Value: "720p", Token: "720p", Rule: "resolution token", Confidence: parser.ConfidenceHigh},
+		"ReleaseGroup": {Field: "ReleaseGroup", Value: "GRP", Token: "GRP", Rule: "scene group suffix", Confidence: parser.ConfidenceMedium},
+	}
+	for field, p := range expected {
+		if got, ok := e.Of(field); !ok || got != p {
+			t.Errorf("%s:\nExpected: %+v\nReceived: %+v", field, p, got)
+		}
+	}
+	if _, ok := e.Of("LastEpisode"); ok {
+		t.Errorf("expected no provenance for an unset field")
+	}
+	fields := e.All()
+	if len(fields) == 0 || fields[0].Field != "Title" {
+		t.Errorf("expected the fields in declaration order, got %v", fields)
+	}
+}
+
+func TestTitleVariants(t *testing.T) {
+	tests := map[string][]string{
+		"amélie":           {"amelie"},
+		"fast and furious": {"fast & furious"},
+		"the matrix":       {"matrix"},
+		"rocky ii":         {"rocky 2"},
+		"rocky 2":          {"rocky ii"},
+		"die hard":         {"hard"},
+		"the":              nil,
+	}
+	for title, expected := range tests {
+		if got := parser.TitleVariants(title); !equalStrings(got, expected) {
+			t.Errorf("%s: expected %v, got %v", title, expected, got)
+		}
+	}
+}
+
+func TestTitleKey(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"Amélie", "amelie"},
+		{"The Matrix", "matrix"},
+		{"Rocky II", "rocky 2"},
+		{"Fast & Furious", "Fast and Furious"},
+		{"Marvel's Agents of S.H.I.E.L.D.", "marvels agents of shield"},
+	} {
+		if a, b := parser.TitleKey(pair[0]), parser.TitleKey(pair[1]); a != b {
+			t.Errorf("expected %s and %s to compare equal, got %s and %s", pair[0], pair[1], a, b)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
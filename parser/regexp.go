@@ -11,16 +11,6 @@ type regex struct {
 	sub  *regexp.Regexp
 }
 
-type tvrxp struct {
-	season   regex // ..S01..
-	episode  regex // ..E01..
-	complete regex // ..S01E02..
-}
-
-func (r *tvrxp) matchFull(s string) bool {
-	return r.season.full.MatchString(s) || r.episode.full.MatchString(s) || r.complete.full.MatchString(s)
-}
-
 func mustCompile(str string) regex {
 	return regex{
 		full: regexp.MustCompile(fmt.Sprintf("^%s$", str)),
@@ -29,39 +19,72 @@ func mustCompile(str string) regex {
 }
 
 var (
-	seasonPattern   = `s(?P<season>\d{1,2})`
-	episode1Pattern = `e(?P<episode1>\d{2,4})`
-	episode2Pattern = `e(?P<episode2>\d{2,4})`
+	seasonPattern  = `s(?P<season>\d{1,2})`
+	episodePattern = `e(?P<episode>\d{2,4})`
 
 	yearRegEx    = mustCompile(`(?P<year>(19|20)\d{2})`)
 	seasonRegEx  = mustCompile(seasonPattern)
-	episodeRegEx = mustCompile(episode1Pattern)
+	episodeRegEx = mustCompile(episodePattern)
+
+	// episodeTokenRegEx matches the tokens a title ends with, e.g. s01, s01e02, e01e02 or 1x02.
+	episodeTokenRegEx = regexp.MustCompile(`^(s\d{1,2}(e\d{2,4})*|(e\d{2,4})+|\d{1,2}x\d{2,4})$`)
 
-	singleEpisode = tvrxp{
-		season:   mustCompile(seasonPattern),
-		episode:  mustCompile(episode1Pattern),
-		complete: mustCompile(seasonPattern + episode1Pattern),
+	// Episodes and episode ranges, the episodes group holds all of their numbers:
+	// S01E01, S01E01-E03, S01E01-03, S01E01E02E03, 1x01, 01x01, 1x01-03, E01E02
+	// and Season 2 Episode 7.
+	episodeRegExList = []*regexp.Regexp{
+		regexp.MustCompile(`(?:^|[^a-z0-9])s(?P<season>\d{1,2})(?P<episodes>e\d{2,4}(?:-?e\d{2,4}|-\d{2,4})*)(?:[^a-z0-9]|$)`),
+		regexp.MustCompile(`(?:^|[^a-z0-9])(?P<season>\d{1,2})x(?P<episodes>\d{2,4}(?:-(?:\d{1,2}x)?\d{2,4})*)(?:[^a-z0-9]|$)`),
+		regexp.MustCompile(`(?:^|[^a-z0-9])(?P<episodes>e\d{2,4}(?:-?e\d{2,4}|-\d{2,4})*)(?:[^a-z0-9]|$)`),
+		regexp.MustCompile(`(?:^|[^a-z0-9])(?:season[ ._-]?(?P<season>\d{1,2})[ ._-]*)?episode[ ._-]?(?P<episode>\d{1,4})(?:[^a-z0-9]|$)`),
 	}
+	episodeNumberRegEx = regexp.MustCompile(`(?:^|[-e])(?:\d{1,2}x)?(\d{2,4})`)
 
-	dualEpisode = tvrxp{
-		season:   mustCompile(seasonPattern),
-		episode:  mustCompile(episode1Pattern + episode2Pattern),
-		complete: mustCompile(seasonPattern + episode1Pattern + episode2Pattern),
+	// Season 2, without an episode.
+	seasonWordRegEx = regexp.MustCompile(`(?:^|[^a-z0-9])season[ ._-]?(?P<season>\d{1,2})(?:[^a-z0-9]|$)`)
+
+	// Part 3 of a mini series, it is taken as an episode of the first season.
+	partRegEx = regexp.MustCompile(`(?:^|[^a-z0-9])part[ ._-]?(?P<episode>\d{1,2})(?:[^a-z0-9]|$)`)
+
+	// Show.2021.03.15.Guest, Show 2021-03-15 or Show.15.03.2021
+	airDateRegExList = []*regexp.Regexp{
+		regexp.MustCompile(`(?:^|\D)(?P<year>(?:19|20)\d{2})([.\-_ ])(?P<month>\d{2})([.\-_ ])(?P<day>\d{2})(?:\D|$)`),
+		regexp.MustCompile(`(?:^|\D)(?P<day>\d{2})([.\-_ ])(?P<month>\d{2})([.\-_ ])(?P<year>(?:19|20)\d{2})(?:\D|$)`),
+	}
+
+	// Movie.1999.1080p.BluRay.x264-GROUP.mkv
+	sceneGroupRegEx = regexp.MustCompile(`-([A-Za-z0-9]+)(?:\.[A-Za-z0-9]{2,4})?$`)
+
+	// [Group] Show Title - 137 [1080p].mkv
+	bracketRegEx      = regexp.MustCompile(`\[[^\]]*\]`)
+	releaseGroupRegEx = regexp.MustCompile(`(?:^|/)\[(?P<group>[^\]]+)\]`)
+	absoluteRegExList = []*regexp.Regexp{
+		regexp.MustCompile(`^(?P<title>.+?)\s+-\s+(?P<absolute>\d{2,4})(?:v\d)?(?:[\s.\[(]|$)`),
+		regexp.MustCompile(`^\[[^\]]+\]\s*(?P<title>[^\[]+?)\s+(?P<absolute>\d{2,4})(?:v\d)?\s*(?:[\[(]|\.[a-z0-9]+$|$)`),
 	}
 
 	tvAlternativeRegExList = []*regexp.Regexp{
 		// Show Title S01/1 - Title.mkv
-		regexp.MustCompile(`.*s(?P<season>\d{1,2}).*/(?P<episode1>\d{1,4}).+`),
+		regexp.MustCompile(`.*s(?P<season>\d{1,2}).*/(?P<episode>\d{1,4}).+`),
 	}
 )
 
-func populateResultFromRxpList(rxps []*regexp.Regexp, s string) Result {
-	result := Result{}
+// getLastResultFromRxpList returns the result and the text of the match that
+// ends last in s, as a file name comes after its folder and is more specific.
+// Of two matches with the same end the longer one wins, e.g. s01e01-e03 over e03.
+func getLastResultFromRxpList(rxps []*regexp.Regexp, s string) (Result, string) {
+	result, start, end := Result{}, -1, -1
 	for _, rxp := range rxps {
-		r := getResultFromRegEx(rxp, s)
-		result.mergeIn(r)
+		for _, loc := range rxp.FindAllStringIndex(s, -1) {
+			if loc[1] > end || (loc[1] == end && loc[0] < start) {
+				result, start, end = getResultFromRegEx(rxp, s[loc[0]:loc[1]]), loc[0], loc[1]
+			}
+		}
 	}
-	return result
+	if start < 0 {
+		return result, ""
+	}
+	return result, s[start:end]
 }
 
 func getBestResultFromRxpList(rxps []*regexp.Regexp, s string) Result {
@@ -98,13 +121,22 @@ func getResultFromRegEx(rxp *regexp.Regexp, s string) Result {
 		year, _ := strconv.Atoi(match)
 		result.Year = year
 	}
-	if match, ok := paramsMap["episode1"]; ok {
+	if match, ok := paramsMap["episode"]; ok {
 		episode, _ := strconv.Atoi(match)
-		result.Episode1 = episode
+		result.Episode = episode
 	}
-	if match, ok := paramsMap["episode2"]; ok {
-		episode, _ := strconv.Atoi(match)
-		result.Episode2 = episode
+	if match, ok := paramsMap["episodes"]; ok {
+		var episodes []int
+		for _, m := range episodeNumberRegEx.FindAllStringSubmatch(match, -1) {
+			episode, _ := strconv.Atoi(m[1])
+			episodes = append(episodes, episode)
+		}
+		if len(episodes) > 0 {
+			result.Episode = episodes[0]
+			if last := episodes[len(episodes)-1]; last > result.Episode {
+				result.LastEpisode = last
+			}
+		}
 	}
 	return result
 }
@@ -1,5 +1,7 @@
 package parser
 
+import "time"
+
 type ParseBool int
 
 const (
@@ -8,16 +10,42 @@ const (
 	False
 )
 
+var parseBoolNames = map[ParseBool]string{
+	Unknown: "--",
+	True:    "true",
+	False:   "false",
+}
+
+// String returns the string representation of b.
+func (b ParseBool) String() string {
+	return parseBoolNames[b]
+}
+
 type Result struct {
 	Title string
 
 	MediaType MediaType
 
-	Year     int
-	Season   int
-	Episode1 int
-	Episode2 int
-	Special  ParseBool
+	Year    int
+	Season  int
+	Episode int
+	Special ParseBool
+
+	// LastEpisode of a multi episode file, e.g. 3 for S01E01-E03.
+	// It is zero for a single episode.
+	LastEpisode int
+
+	// AbsoluteEpisode is the episode number counted over all
+	// seasons, as used by anime releases without a season.
+	AbsoluteEpisode int
+
+	// AirDate of daily shows, named by date instead of episode.
+	AirDate time.Time
+
+	ReleaseGroup string
+
+	// Edition of a movie, e.g. Director's Cut.
+	Edition string
 
 	Resolution   Resolution
 	Source       Source
@@ -25,6 +53,12 @@ type Result struct {
 	Remux        ParseBool
 	Proper       ParseBool
 	DualLanguage ParseBool
+
+	VideoCodec    VideoCodec
+	AudioCodec    AudioCodec
+	AudioChannels AudioChannels
+	HDR           HDR
+	BitDepth      BitDepth
 }
 
 func (r *Result) IsMovie() bool {
@@ -35,6 +69,18 @@ func (r *Result) IsTV() bool {
 	return r.MediaType == MediaTypeTV
 }
 
+// Episodes returns all episode numbers, e.g. 1, 2 and 3 for S01E01-E03.
+func (r *Result) Episodes() []int {
+	if r.Episode == 0 {
+		return nil
+	}
+	episodes := []int{r.Episode}
+	for e := r.Episode + 1; e <= r.LastEpisode; e++ {
+		episodes = append(episodes, e)
+	}
+	return episodes
+}
+
 func (r *Result) score() int {
 	score := 0
 	if r.Title != "" {
@@ -49,7 +95,7 @@ func (r *Result) score() int {
 	if r.Season != 0 {
 		score += 1
 	}
-	if r.Episode1 != 0 {
+	if r.Episode != 0 {
 		score += 1
 	}
 	if r.Resolution != 0 {
@@ -73,9 +119,42 @@ func (r *Result) score() int {
 	if r.Special != Unknown {
 		score += 1
 	}
+	if r.AbsoluteEpisode != 0 {
+		score += 1
+	}
+	if r.ReleaseGroup != "" {
+		score += 1
+	}
+	if r.Edition != "" {
+		score += 1
+	}
+	if !r.AirDate.IsZero() {
+		score += 1
+	}
+	if r.VideoCodec != VideoCodecNA {
+		score += 1
+	}
+	if r.AudioCodec != AudioCodecNA {
+		score += 1
+	}
+	if r.AudioChannels != ChannelsNA {
+		score += 1
+	}
+	if r.HDR != HDRNA {
+		score += 1
+	}
+	if r.BitDepth != BitDepthNA {
+		score += 1
+	}
 	return score
 }
 
+// Merge returns a copy of r with all fields replaced that are set in other.
+func (r Result) Merge(other Result) Result {
+	r.mergeIn(other)
+	return r
+}
+
 func (r *Result) mergeIn(other Result) {
 	if other.Title != "" {
 		r.Title = other.Title
@@ -89,11 +168,11 @@ func (r *Result) mergeIn(other Result) {
 	if other.Season != 0 {
 		r.Season = other.Season
 	}
-	if other.Episode1 != 0 {
-		r.Episode1 = other.Episode1
-	}
-	if other.Episode2 != 0 {
-		r.Episode2 = other.Episode2
+	if other.Episode != 0 {
+		r.Episode = other.Episode
+		r.LastEpisode = other.LastEpisode
+	} else if other.LastEpisode != 0 {
+		r.LastEpisode = other.LastEpisode
 	}
 	if other.Resolution != 0 {
 		r.Resolution = other.Resolution
@@ -116,4 +195,31 @@ func (r *Result) mergeIn(other Result) {
 	if other.Special != Unknown {
 		r.Special = other.Special
 	}
+	if other.AbsoluteEpisode != 0 {
+		r.AbsoluteEpisode = other.AbsoluteEpisode
+	}
+	if other.ReleaseGroup != "" {
+		r.ReleaseGroup = other.ReleaseGroup
+	}
+	if other.Edition != "" {
+		r.Edition = other.Edition
+	}
+	if !other.AirDate.IsZero() {
+		r.AirDate = other.AirDate
+	}
+	if other.VideoCodec != VideoCodecNA {
+		r.VideoCodec = other.VideoCodec
+	}
+	if other.AudioCodec != AudioCodecNA {
+		r.AudioCodec = other.AudioCodec
+	}
+	if other.AudioChannels != ChannelsNA {
+		r.AudioChannels = other.AudioChannels
+	}
+	if other.HDR != HDRNA {
+		r.HDR = other.HDR
+	}
+	if other.BitDepth != BitDepthNA {
+		r.BitDepth = other.BitDepth
+	}
 }
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// titleReplacer removes apostrophes, Marvel's becomes marvels
+	// instead of marvel s, and spells out ampersands.
+	titleReplacer = strings.NewReplacer("'", "", "’", "", "`", "", "&", " and ")
+
+	// leadingArticles are left out when titles are compared.
+	leadingArticles = map[string]bool{
+		"the": true, "a": true, "an": true,
+		"der": true, "die": true, "das": true,
+		"le": true, "la": true, "les": true,
+		"el": true, "los": true, "il": true,
+	}
+
+	// romanNumerals of sequels, single letters such as the I
+	// of I, Robot or the X of Malcolm X are left alone.
+	romanNumerals = map[string]int{
+		"ii": 2, "iii": 3, "iv": 4, "vi": 6, "vii": 7, "viii": 8, "ix": 9,
+		"xi": 11, "xii": 12, "xiii": 13, "xiv": 14, "xv": 15,
+		"xvi": 16, "xvii": 17, "xviii": 18, "xix": 19, "xx": 20,
+	}
+
+	diacritics = map[rune]string{
+		'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+		'ç': "c", 'č': "c", 'ć': "c",
+		'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ě': "e",
+		'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+		'ñ': "n", 'ń': "n", 'ł': "l",
+		'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+		'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ů': "u",
+		'ý': "y", 'ÿ': "y", 'ß': "ss", 'š': "s", 'ś': "s", 'ž': "z", 'ź': "z", 'ż': "z", 'ř': "r",
+	}
+)
+
+// titleTokens tokenizes the title part of a name, with apostrophes
+// removed, ampersands spelled out and brackets left out.
+func titleTokens(s string) []string {
+	return tokenize(titleReplacer.Replace(bracketRegEx.ReplaceAllString(s, " ")))
+}
+
+// joinAcronyms rebuilds acronyms that tokenizing took apart,
+// e.g. the s h i e l d of S.H.I.E.L.D becomes shield.
+func joinAcronyms(tokens []string) []string {
+	var result []string
+	var acronym []string
+	flush := func() {
+		if len(acronym) > 1 {
+			result = append(result, strings.Join(acronym, ""))
+		} else {
+			result = append(result, acronym...)
+		}
+		acronym = nil
+	}
+	for _, t := range tokens {
+		if r := []rune(t); len(r) == 1 && unicode.IsLetter(r[0]) {
+			acronym = append(acronym, t)
+			continue
+		}
+		flush()
+		result = append(result, t)
+	}
+	flush()
+	return result
+}
+
+// TitleVariants returns alternative spellings of a parsed title to search
+// for if the title itself yields nothing. These are the title without
+// diacritics, with an ampersand, without a leading article and with
+// arabic instead of roman numerals or the other way around.
+func TitleVariants(title string) []string {
+	words := strings.Fields(strings.ToLower(title))
+	folded := strings.Fields(foldDiacritics(strings.Join(words, " ")))
+	candidates := [][]string{
+		folded,
+		replaceWord(words, "and", "&"),
+		withoutArticle(words),
+		toArabic(words),
+		toRoman(words),
+		toArabic(withoutArticle(folded)),
+	}
+
+	var variants []string
+	seen := map[string]bool{strings.Join(words, " "): true}
+	for _, c := range candidates {
+		v := strings.Join(c, " ")
+		if v != "" && !seen[v] {
+			seen[v] = true
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+// TitleKey returns the form in which titles are compared: lower case,
+// without diacritics, punctuation and a leading article and with arabic
+// numerals, e.g. "Amélie" and "amelie" or "Rocky II" and "rocky 2".
+func TitleKey(title string) string {
+	words := strings.Fields(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return r
+		}
+		return ' '
+	}, foldDiacritics(titleReplacer.Replace(strings.ToLower(title)))))
+	return strings.Join(toArabic(withoutArticle(words)), "")
+}
+
+func foldDiacritics(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if f, ok := diacritics[r]; ok {
+			b.WriteString(f)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// withoutArticle removes a leading article, unless it is all there is.
+func withoutArticle(words []string) []string {
+	if len(words) > 1 && leadingArticles[words[0]] {
+		return words[1:]
+	}
+	return words
+}
+
+func replaceWord(words []string, old, new string) []string {
+	result := make([]string, len(words))
+	for i, w := range words {
+		if w == old {
+			w = new
+		}
+		result[i] = w
+	}
+	return result
+}
+
+func toArabic(words []string) []string {
+	result := make([]string, len(words))
+	for i, w := range words {
+		if n, ok := romanNumerals[w]; ok {
+			w = strconv.Itoa(n)
+		}
+		result[i] = w
+	}
+	return result
+}
+
+// toRoman converts the numbers after the first word, e.g. rocky 2 becomes rocky ii.
+func toRoman(words []string) []string {
+	result := make([]string, len(words))
+	copy(result, words)
+	for i := 1; i < len(result); i++ {
+		n, err := strconv.Atoi(result[i])
+		if err != nil {
+			continue
+		}
+		for roman, value := range romanNumerals {
+			if value == n {
+				result[i] = roman
+			}
+		}
+	}
+	return result
+}
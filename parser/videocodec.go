@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// VideoCodec is the internal representation of a video codec such as HEVC.
+type VideoCodec int
+
+// All known video codecs.
+const (
+	VideoCodecNA VideoCodec = iota
+	H264
+	H265
+	XviD
+	DivX
+	VC1
+	MPEG2
+	AV1
+	VP9
+)
+
+// Video codecs mapped to their string representations.
+var (
+	videoCodecNames = map[VideoCodec]string{
+		VideoCodecNA: "--",
+		H264:         "AVC",
+		H265:         "HEVC",
+		XviD:         "XviD",
+		DivX:         "DivX",
+		VC1:          "VC-1",
+		MPEG2:        "MPEG-2",
+		AV1:          "AV1",
+		VP9:          "VP9",
+	}
+
+	videoCodecMap = map[string]VideoCodec{
+		"avc":    H264,
+		"h264":   H264,
+		"x264":   H264,
+		"hevc":   H265,
+		"h265":   H265,
+		"x265":   H265,
+		"xvid":   XviD,
+		"divx":   DivX,
+		"vc1":    VC1,
+		"vc-1":   VC1,
+		"mpeg2":  MPEG2,
+		"mpeg-2": MPEG2,
+		"av1":    AV1,
+		"vp9":    VP9,
+	}
+
+	// videoCodecRegExList is tried in order, the first match wins.
+	videoCodecRegExList = []struct {
+		codec VideoCodec
+		rxp   *regexp.Regexp
+	}{
+		{H265, regexp.MustCompile(`(?:[xh]\.?265|(?:^|[^a-z0-9])hevc)(?:[^a-z0-9]|$)`)},
+		{H264, regexp.MustCompile(`(?:[xh]\.?264|(?:^|[^a-z0-9])avc)(?:[^a-z0-9]|$)`)},
+		{XviD, regexp.MustCompile(`(?:^|[^a-z0-9])xvid(?:[^a-z0-9]|$)`)},
+		{DivX, regexp.MustCompile(`(?:^|[^a-z0-9])divx(?:[^a-z0-9]|$)`)},
+		{VC1, regexp.MustCompile(`(?:^|[^a-z0-9])vc-?1(?:[^a-z0-9]|$)`)},
+		{MPEG2, regexp.MustCompile(`(?:^|[^a-z0-9])mpeg-?2(?:[^a-z0-9]|$)`)},
+		{AV1, regexp.MustCompile(`(?:^|[^a-z0-9])av1(?:[^a-z0-9]|$)`)},
+		{VP9, regexp.MustCompile(`(?:^|[^a-z0-9])vp9(?:[^a-z0-9]|$)`)},
+	}
+)
+
+// ParseVideoCodec parses the given string to a video codec.
+func ParseVideoCodec(codec string) (VideoCodec, error) {
+	if c, ok := videoCodecMap[strings.ToLower(codec)]; ok {
+		return c, nil
+	}
+	if codec == "" {
+		return VideoCodecNA, nil
+	}
+	return VideoCodecNA, fmt.Errorf("unknown video codec: %s", codec)
+}
+
+// String returns the string representation of c.
+func (c VideoCodec) String() string {
+	return videoCodecNames[c]
+}
+
+// VideoCodecNames returns a map containing all string representations.
+func VideoCodecNames() map[VideoCodec]string {
+	return videoCodecNames
+}
@@ -0,0 +1,367 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/florianehmke/plexname/parser"
+)
+
+// Matroska element IDs, see https://www.matroska.org/technical/elements.html
+const (
+	idEBML                    = 0x1a45dfa3
+	idSegment                 = 0x18538067
+	idInfo                    = 0x1549a966
+	idTimestampScale          = 0x2ad7b1
+	idDuration                = 0x4489
+	idTracks                  = 0x1654ae6b
+	idTrackEntry              = 0xae
+	idTrackType               = 0x83
+	idCodecID                 = 0x86
+	idLanguage                = 0x22b59c
+	idLanguageIETF            = 0x22b59d
+	idVideo                   = 0xe0
+	idPixelWidth              = 0xb0
+	idPixelHeight             = 0xba
+	idColour                  = 0x55b0
+	idTransferCharacteristics = 0x55ba
+	idBlockAdditionMapping    = 0x41e4
+	idBlockAddIDType          = 0x41e7
+	idCluster                 = 0x1f43b675
+)
+
+const (
+	trackTypeVideo = 1
+	trackTypeAudio = 2
+)
+
+// unknownSize marks elements whose size is not known upfront.
+const unknownSize = -1
+
+var matroskaCodecs = map[string]parser.VideoCodec{
+	"V_MPEG4/ISO/AVC":  parser.H264,
+	"V_MPEGH/ISO/HEVC": parser.H265,
+	"V_AV1":            parser.AV1,
+	"V_VP9":            parser.VP9,
+	"V_MPEG2":          parser.MPEG2,
+	"V_MS/VFW/FOURCC":  parser.VideoCodecNA,
+}
+
+// readMatroska reads the segment info and the tracks, both are
+// found before the first cluster in practically every file.
+func readMatroska(r io.Reader) (Info, error) {
+	id, size, err := readElementHeader(r)
+	if err != nil {
+		return Info{}, err
+	}
+	if id != idEBML || size == unknownSize {
+		return Info{}, errors.New("no ebml header")
+	}
+	if err := skip(r, size); err != nil {
+		return Info{}, err
+	}
+	id, _, err = readElementHeader(r)
+	if err != nil {
+		return Info{}, err
+	}
+	if id != idSegment {
+		return Info{}, errors.New("no matroska segment")
+	}
+
+	info := Info{}
+	var hasTracks bool
+	for {
+		id, size, err := readElementHeader(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return info, err
+		}
+		if id == idCluster && hasTracks {
+			break
+		}
+		if size == unknownSize {
+			break
+		}
+		switch id {
+		case idInfo, idTracks:
+			data, err := readData(r, size)
+			if err != nil {
+				return info, err
+			}
+			if id == idInfo {
+				err = readSegmentInfo(data, &info)
+			} else {
+				err = readTracks(data, &info)
+				hasTracks = true
+			}
+			if err != nil {
+				return info, err
+			}
+		default:
+			if err := skip(r, size); err != nil {
+				return info, err
+			}
+		}
+	}
+	if !hasTracks {
+		return info, errors.New("no matroska tracks")
+	}
+	return info, nil
+}
+
+func readSegmentInfo(data []byte, info *Info) error {
+	scale := uint64(1000000)
+	var duration float64
+	err := elements(data, func(id uint64, payload []byte) error {
+		switch id {
+		case idTimestampScale:
+			scale = readUint(payload)
+		case idDuration:
+			duration = readFloat(payload)
+		}
+		return nil
+	})
+	info.Duration = time.Duration(duration * float64(scale))
+	return err
+}
+
+func readTracks(data []byte, info *Info) error {
+	return elements(data, func(id uint64, payload []byte) error {
+		if id == idTrackEntry {
+			return readTrackEntry(payload, info)
+		}
+		return nil
+	})
+}
+
+func readTrackEntry(data []byte, info *Info) error {
+	var trackType uint64
+	var codec, lang, langIETF string
+	var width, height int
+	var transfer uint64
+	var dolbyVision bool
+	err := elements(data, func(id uint64, payload []byte) error {
+		switch id {
+		case idTrackType:
+			trackType = readUint(payload)
+		case idCodecID:
+			codec = readString(payload)
+		case idLanguage:
+			lang = readString(payload)
+		case idLanguageIETF:
+			langIETF = readString(payload)
+		case idVideo:
+			return elements(payload, func(id uint64, payload []byte) error {
+				switch id {
+				case idPixelWidth:
+					width = int(readUint(payload))
+				case idPixelHeight:
+					height = int(readUint(payload))
+				case idColour:
+					return elements(payload, func(id uint64, payload []byte) error {
+						if id == idTransferCharacteristics {
+							transfer = readUint(payload)
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+		case idBlockAdditionMapping:
+			return elements(payload, func(id uint64, payload []byte) error {
+				if id == idBlockAddIDType {
+					t := readUint(payload)
+					dolbyVision = dolbyVision || t == fourCC("dvcC") || t == fourCC("dvvC")
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch trackType {
+	case trackTypeVideo:
+		if info.Width != 0 {
+			// Only the first video track is of interest.
+			return nil
+		}
+		info.Width, info.Height = width, height
+		info.VideoCodec = matroskaCodecs[codec]
+		info.HDR = hdrFor(transfer, dolbyVision)
+	case trackTypeAudio:
+		if langIETF != "" {
+			lang = strings.SplitN(langIETF, "-", 2)[0]
+		}
+		if lang == "" {
+			// The default of the Language element.
+			lang = "eng"
+		}
+		if lang != "und" {
+			info.AudioLanguages = append(info.AudioLanguages, lang)
+		}
+	}
+	return nil
+}
+
+// hdrFor maps the transfer characteristics (ITU-T H.273) to an HDR format.
+func hdrFor(transfer uint64, dolbyVision bool) parser.HDR {
+	switch {
+	case dolbyVision:
+		return parser.DolbyVision
+	case transfer == 16:
+		return parser.HDR10
+	case transfer == 18:
+		return parser.HLG
+	}
+	return parser.HDRNA
+}
+
+// elements calls fn for each element in data.
+func elements(data []byte, fn func(id uint64, payload []byte) error) error {
+	for len(data) > 0 {
+		id, n := vint(data, true)
+		if n == 0 {
+			return errors.New("invalid element id")
+		}
+		data = data[n:]
+		size, n := vint(data, false)
+		if n == 0 || size > uint64(len(data)-n) {
+			return errors.New("invalid element size")
+		}
+		data = data[n:]
+		if err := fn(id, data[:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// vint decodes the variable length integer at the start of b and returns it
+// along with its length, which is zero if b does not start with a valid one.
+// IDs keep their length marker, sizes do not.
+func vint(b []byte, keepMarker bool) (uint64, int) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0
+	}
+	n := 1
+	for mask := byte(0x80); b[0]&mask == 0; mask >>= 1 {
+		n++
+	}
+	if len(b) < n {
+		return 0, 0
+	}
+	v := uint64(b[0])
+	if !keepMarker {
+		v &= uint64(0xff >> uint(n))
+	}
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c)
+	}
+	return v, n
+}
+
+// readElementHeader reads the ID and the size of the next element,
+// the size is unknownSize if all of its bits are set.
+func readElementHeader(r io.Reader) (uint64, int64, error) {
+	b, err := readVint(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	id, _ := vint(b, true)
+	b, err = readVint(r)
+	if err != nil {
+		return 0, 0, unexpectedEOF(err)
+	}
+	size, n := vint(b, false)
+	if size == 1<<uint(7*n)-1 {
+		return id, unknownSize, nil
+	}
+	return id, int64(size), nil
+}
+
+// readVint reads the bytes of the next variable length integer.
+func readVint(r io.Reader) ([]byte, error) {
+	first := make([]byte, 1)
+	if _, err := io.ReadFull(r, first); err != nil {
+		return nil, err
+	}
+	if first[0] == 0 {
+		return nil, errors.New("invalid variable length integer")
+	}
+	n := 1
+	for mask := byte(0x80); first[0]&mask == 0; mask >>= 1 {
+		n++
+	}
+	b := make([]byte, n)
+	b[0] = first[0]
+	if _, err := io.ReadFull(r, b[1:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return b, nil
+}
+
+func readData(r io.Reader, size int64) ([]byte, error) {
+	if size > maxElementSize {
+		return nil, fmt.Errorf("element of %d bytes is too large", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return data, nil
+}
+
+// skip skips size bytes of r, by seeking if r supports it.
+func skip(r io.Reader, size int64) error {
+	if s, ok := r.(io.Seeker); ok {
+		_, err := s.Seek(size, io.SeekCurrent)
+		return err
+	}
+	_, err := io.CopyN(ioutil.Discard, r, size)
+	return unexpectedEOF(err)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func readFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func readString(b []byte) string {
+	return strings.TrimRight(string(b), "\x00")
+}
+
+func fourCC(s string) uint64 {
+	return uint64(binary.BigEndian.Uint32([]byte(s)))
+}
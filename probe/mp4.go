@@ -0,0 +1,232 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/florianehmke/plexname/parser"
+)
+
+var mp4Codecs = map[string]parser.VideoCodec{
+	"avc1": parser.H264,
+	"avc3": parser.H264,
+	"hvc1": parser.H265,
+	"hev1": parser.H265,
+	"dvh1": parser.H265,
+	"dvhe": parser.H265,
+	"av01": parser.AV1,
+	"vp09": parser.VP9,
+	"mp4v": parser.VideoCodecNA,
+}
+
+// readMP4 finds the moov box among the top level boxes and reads it.
+func readMP4(r io.Reader) (Info, error) {
+	for {
+		typ, size, err := readBoxHeader(r)
+		if err == io.EOF {
+			return Info{}, errors.New("no mp4 moov box")
+		}
+		if err != nil {
+			return Info{}, err
+		}
+		if typ != "moov" {
+			if size < 0 {
+				return Info{}, errors.New("no mp4 moov box")
+			}
+			if err := skip(r, size); err != nil {
+				return Info{}, err
+			}
+			continue
+		}
+		if size < 0 {
+			return Info{}, errors.New("mp4 moov box without size")
+		}
+		data, err := readData(r, size)
+		if err != nil {
+			return Info{}, err
+		}
+		info := Info{}
+		err = boxes(data, func(typ string, payload []byte) error {
+			switch typ {
+			case "mvhd":
+				readMovieHeader(payload, &info)
+			case "trak":
+				return readTrack(payload, &info)
+			}
+			return nil
+		})
+		return info, err
+	}
+}
+
+// readBoxHeader reads the type and the payload size of the next box,
+// the size is negative for a box that extends to the end of the file.
+func readBoxHeader(r io.Reader) (string, int64, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", 0, err
+	}
+	typ := string(header[4:])
+	size := int64(binary.BigEndian.Uint32(header))
+	switch size {
+	case 0:
+		return typ, -1, nil
+	case 1:
+		if _, err := io.ReadFull(r, header); err != nil {
+			return "", 0, unexpectedEOF(err)
+		}
+		size = int64(binary.BigEndian.Uint64(header)) - 16
+	default:
+		size -= 8
+	}
+	if size < 0 {
+		return "", 0, errors.New("invalid mp4 box size")
+	}
+	return typ, size, nil
+}
+
+func readMovieHeader(data []byte, info *Info) {
+	var timescale, duration uint64
+	switch {
+	case len(data) >= 32 && data[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(data[20:]))
+		duration = binary.BigEndian.Uint64(data[24:])
+	case len(data) >= 20:
+		timescale = uint64(binary.BigEndian.Uint32(data[12:]))
+		duration = uint64(binary.BigEndian.Uint32(data[16:]))
+	}
+	if timescale == 0 {
+		return
+	}
+	// Computed in floats, duration * time.Second overflows for fine timescales.
+	info.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+}
+
+// readTrack reads the handler, language and sample description of a trak box.
+func readTrack(data []byte, info *Info) error {
+	var handler, lang string
+	var entry []byte
+	var format string
+	err := walk(data, []string{"mdia"}, func(typ string, payload []byte) error {
+		switch typ {
+		case "hdlr":
+			if len(payload) >= 12 {
+				handler = string(payload[8:12])
+			}
+		case "mdhd":
+			lang = readMediaLanguage(payload)
+		case "minf":
+			return walk(payload, []string{"stbl"}, func(typ string, payload []byte) error {
+				if typ == "stsd" && len(payload) >= 8 {
+					return boxes(payload[8:], func(typ string, payload []byte) error {
+						if entry == nil {
+							format, entry = typ, payload
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch handler {
+	case "vide":
+		if info.Width != 0 || len(entry) < 28 {
+			// Only the first video track is of interest.
+			return nil
+		}
+		info.Width = int(binary.BigEndian.Uint16(entry[24:]))
+		info.Height = int(binary.BigEndian.Uint16(entry[26:]))
+		info.VideoCodec = mp4Codecs[format]
+		info.HDR = readVisualHDR(format, entry)
+	case "soun":
+		if lang != "" && lang != "und" {
+			info.AudioLanguages = append(info.AudioLanguages, lang)
+		}
+	}
+	return nil
+}
+
+// readMediaLanguage returns the packed ISO 639-2/T code of an mdhd box.
+func readMediaLanguage(data []byte) string {
+	offset := 20
+	if len(data) > 0 && data[0] == 1 {
+		offset = 32
+	}
+	if len(data) < offset+2 {
+		return ""
+	}
+	packed := binary.BigEndian.Uint16(data[offset:])
+	return string([]byte{
+		byte(packed>>10&0x1f) + 0x60,
+		byte(packed>>5&0x1f) + 0x60,
+		byte(packed&0x1f) + 0x60,
+	})
+}
+
+// readVisualHDR looks at the colr and dolby vision boxes that
+// follow the 78 bytes of fields of a visual sample entry.
+func readVisualHDR(format string, entry []byte) parser.HDR {
+	dolbyVision := format == "dvh1" || format == "dvhe"
+	var transfer uint64
+	if len(entry) > 78 {
+		boxes(entry[78:], func(typ string, payload []byte) error {
+			switch typ {
+			case "dvcC", "dvvC":
+				dolbyVision = true
+			case "colr":
+				if len(payload) >= 8 && string(payload[:4]) == "nclx" {
+					transfer = uint64(binary.BigEndian.Uint16(payload[6:]))
+				}
+			}
+			return nil
+		})
+	}
+	return hdrFor(transfer, dolbyVision)
+}
+
+// walk calls fn for the boxes in data, descending into the given containers.
+func walk(data []byte, containers []string, fn func(typ string, payload []byte) error) error {
+	return boxes(data, func(typ string, payload []byte) error {
+		for _, c := range containers {
+			if typ == c {
+				return walk(payload, containers, fn)
+			}
+		}
+		return fn(typ, payload)
+	})
+}
+
+// boxes calls fn for each box in data.
+func boxes(data []byte, fn func(typ string, payload []byte) error) error {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		typ := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return errors.New("invalid mp4 box size")
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return errors.New("invalid mp4 box size")
+		}
+		if err := fn(typ, data[header:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
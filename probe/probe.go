@@ -0,0 +1,115 @@
+// Package probe reads the headers of Matroska and MP4 containers to find
+// out what a file really contains, without relying on external tools.
+package probe
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/florianehmke/plexname/parser"
+)
+
+// ErrUnknownFormat is returned for files that are neither Matroska nor MP4.
+var ErrUnknownFormat = errors.New("unknown container format")
+
+// maxElementSize limits how much of a header is read into memory.
+const maxElementSize = 64 << 20
+
+// Info is what the container headers tell about a file.
+type Info struct {
+	Width  int
+	Height int
+
+	VideoCodec parser.VideoCodec
+	HDR        parser.HDR
+
+	// AudioLanguages of all audio tracks as found in the
+	// container, e.g. ger or en, undetermined ones are skipped.
+	AudioLanguages []string
+
+	Duration time.Duration
+}
+
+// File probes the container of the file at path.
+func File(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+	return Reader(f)
+}
+
+// Reader probes the container read from r.
+func Reader(r io.ReadSeeker) (Info, error) {
+	magic := make([]byte, 12)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return Info{}, ErrUnknownFormat
+		}
+		return Info{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return Info{}, err
+	}
+	switch {
+	case bytes.Equal(magic[:4], []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		return readMatroska(r)
+	case string(magic[4:8]) == "ftyp":
+		return readMP4(r)
+	}
+	return Info{}, ErrUnknownFormat
+}
+
+// Result returns the probed quality fields as parser result.
+//
+// Language is the first audio language that is not english, plain
+// english releases are not tagged with a language by the parser either.
+// DualLanguage is set if there are at least two audio languages.
+func (i Info) Result() parser.Result {
+	pr := parser.Result{
+		Resolution: resolutionFor(i.Width, i.Height),
+		VideoCodec: i.VideoCodec,
+		HDR:        i.HDR,
+	}
+	seen := map[parser.Language]bool{}
+	for _, code := range i.AudioLanguages {
+		l, ok := parser.ParseLanguageCode(code)
+		if !ok || seen[l] {
+			continue
+		}
+		seen[l] = true
+		if pr.Language == parser.LangNA && l != parser.English {
+			pr.Language = l
+		}
+	}
+	if len(seen) > 1 {
+		pr.DualLanguage = parser.True
+	}
+	return pr
+}
+
+// resolutionFor maps the frame size to a resolution. Widths are
+// checked as well because movies are usually cropped vertically.
+func resolutionFor(width, height int) parser.Resolution {
+	switch {
+	case width == 0 || height == 0:
+		return parser.ResNA
+	case width >= 3200 || height >= 1800:
+		return parser.R2160
+	case width >= 1700 || height >= 1000:
+		return parser.R1080
+	case width >= 1200 || height >= 700:
+		return parser.R720
+	case height >= 560:
+		return parser.R576
+	case height >= 530:
+		return parser.R540
+	case height >= 470:
+		return parser.R480
+	}
+	return parser.R360
+}
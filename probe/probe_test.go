@@ -0,0 +1,205 @@
+package probe_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/probe"
+)
+
+// element encodes a matroska element with an eight byte size.
+func element(id uint32, payload ...[]byte) []byte {
+	var b []byte
+	for i := 24; i >= 0; i -= 8 {
+		if c := byte(id >> uint(i)); c != 0 || len(b) > 0 {
+			b = append(b, c)
+		}
+	}
+	data := bytes.Join(payload, nil)
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(data))|1<<56)
+	return append(append(b, size...), data...)
+}
+
+func uintElement(id uint32, v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return element(id, b)
+}
+
+func floatElement(id uint32, v float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(v))
+	return element(id, b)
+}
+
+// box encodes an mp4 box.
+func box(typ string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b, uint32(len(data)+8))
+	copy(b[4:], typ)
+	return append(b, data...)
+}
+
+func mp4Track(handler, lang string, entry []byte) []byte {
+	mdhd := make([]byte, 24)
+	binary.BigEndian.PutUint16(mdhd[20:], uint16(lang[0]-0x60)<<10|uint16(lang[1]-0x60)<<5|uint16(lang[2]-0x60))
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:], handler)
+	stsd := make([]byte, 8)
+	binary.BigEndian.PutUint32(stsd[4:], 1)
+	return box("trak", box("mdia",
+		box("mdhd", mdhd),
+		box("hdlr", hdlr),
+		box("minf", box("stbl", box("stsd", stsd, entry))),
+	))
+}
+
+func TestMatroska(t *testing.T) {
+	mkv := bytes.Join([][]byte{
+		element(0x1a45dfa3, element(0x4282, []byte("matroska"))),
+		element(0x18538067,
+			element(0x114d9b74),
+			element(0x1549a966,
+				uintElement(0x2ad7b1, 1000000),
+				floatElement(0x4489, 5400000),
+			),
+			element(0x1654ae6b,
+				element(0xae,
+					uintElement(0x83, 1),
+					element(0x86, []byte("V_MPEGH/ISO/HEVC")),
+					element(0xe0,
+						uintElement(0xb0, 3840),
+						uintElement(0xba, 1600),
+						element(0x55b0, uintElement(0x55ba, 16)),
+					),
+				),
+				element(0xae,
+					uintElement(0x83, 2),
+					element(0x86, []byte("A_DTS")),
+					element(0x22b59c, []byte("ger")),
+				),
+				element(0xae,
+					uintElement(0x83, 2),
+					element(0x86, []byte("A_AC3")),
+				),
+			),
+			element(0x1f43b675, uintElement(0xe7, 0)),
+		),
+	}, nil)
+
+	info, err := probe.Reader(bytes.NewReader(mkv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := probe.Info{
+		Width:          3840,
+		Height:         1600,
+		VideoCodec:     parser.H265,
+		HDR:            parser.HDR10,
+		AudioLanguages: []string{"ger", "eng"},
+		Duration:       90 * time.Minute,
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("\nExpected: %+v\nReceived: %+v", expected, info)
+	}
+}
+
+func TestMP4(t *testing.T) {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 2700000)
+
+	visual := make([]byte, 78)
+	binary.BigEndian.PutUint16(visual[24:], 1920)
+	binary.BigEndian.PutUint16(visual[26:], 1080)
+	avc1 := box("avc1", visual, box("dvcC", make([]byte, 24)))
+
+	mp4 := bytes.Join([][]byte{
+		box("ftyp", []byte("isom"), make([]byte, 4)),
+		box("mdat", make([]byte, 32)),
+		box("moov",
+			box("mvhd", mvhd),
+			mp4Track("vide", "und", avc1),
+			mp4Track("soun", "eng", box("mp4a", make([]byte, 28))),
+			mp4Track("soun", "fra", box("ac-3", make([]byte, 28))),
+		),
+	}, nil)
+
+	info, err := probe.Reader(bytes.NewReader(mp4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := probe.Info{
+		Width:          1920,
+		Height:         1080,
+		VideoCodec:     parser.H264,
+		HDR:            parser.DolbyVision,
+		AudioLanguages: []string{"eng", "fra"},
+		Duration:       45 * time.Minute,
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("\nExpected: %+v\nReceived: %+v", expected, info)
+	}
+}
+
+func TestMP4_LongDuration(t *testing.T) {
+	// Version 1 header with a fine timescale, 90 minutes overflow
+	// a time.Duration if multiplied by a second first.
+	mvhd := make([]byte, 112)
+	mvhd[0] = 1
+	binary.BigEndian.PutUint32(mvhd[20:], 10000000)
+	binary.BigEndian.PutUint64(mvhd[24:], 90*60*10000000)
+
+	mp4 := bytes.Join([][]byte{
+		box("ftyp", []byte("isom"), make([]byte, 4)),
+		box("moov", box("mvhd", mvhd)),
+	}, nil)
+
+	info, err := probe.Reader(bytes.NewReader(mp4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != 90*time.Minute {
+		t.Errorf("expected 90m, got %v", info.Duration)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("not a video container")} {
+		if _, err := probe.Reader(bytes.NewReader(data)); err != probe.ErrUnknownFormat {
+			t.Errorf("expected %v, got %v", probe.ErrUnknownFormat, err)
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		info     probe.Info
+		expected parser.Result
+	}{
+		{
+			info:     probe.Info{Width: 1920, Height: 800, VideoCodec: parser.H264, AudioLanguages: []string{"eng"}},
+			expected: parser.Result{Resolution: parser.R1080, VideoCodec: parser.H264},
+		},
+		{
+			info:     probe.Info{Width: 1280, Height: 720, AudioLanguages: []string{"eng", "ger", "en"}},
+			expected: parser.Result{Resolution: parser.R720, Language: parser.German, DualLanguage: parser.True},
+		},
+		{
+			info:     probe.Info{Width: 720, Height: 576, HDR: parser.HLG, AudioLanguages: []string{"fre"}},
+			expected: parser.Result{Resolution: parser.R576, HDR: parser.HLG, Language: parser.French},
+		},
+	}
+	for _, tc := range tests {
+		if pr := tc.info.Result(); !reflect.DeepEqual(pr, tc.expected) {
+			t.Errorf("\nExpected: %+v\nReceived: %+v", tc.expected, pr)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package prompt
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -67,3 +68,26 @@ func (p *prompter) Confirm(question string) (bool, error) {
 	}
 	return p.Confirm(question)
 }
+
+// Recorder is a Prompter for runs without a terminal.
+// It fails every question but remembers it, so it can be asked later.
+type Recorder struct {
+	Questions []string
+}
+
+func (r *Recorder) AskNumber(question string) (int, error) {
+	return 0, r.record(question)
+}
+
+func (r *Recorder) AskString(question string) (string, error) {
+	return "", r.record(question)
+}
+
+func (r *Recorder) Confirm(question string) (bool, error) {
+	return false, r.record(question)
+}
+
+func (r *Recorder) record(question string) error {
+	r.Questions = append(r.Questions, question)
+	return errors.New("no terminal to prompt on, question recorded")
+}
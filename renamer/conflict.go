@@ -0,0 +1,250 @@
+package renamer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/florianehmke/plexname/log"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/probe"
+)
+
+// ConflictPolicy decides what happens if two files would end up at the same target.
+type ConflictPolicy int
+
+// All known conflict policies.
+const (
+	ConflictFail ConflictPolicy = iota
+	ConflictSkip
+	ConflictSuffix
+	ConflictReplaceIfBetter
+)
+
+var (
+	conflictPolicyNames = map[ConflictPolicy]string{
+		ConflictFail:            "fail",
+		ConflictSkip:            "skip",
+		ConflictSuffix:          "suffix",
+		ConflictReplaceIfBetter: "replace-if-better",
+	}
+
+	// sourceRanks orders sources from worst to best.
+	sourceRanks = map[parser.Source]int{
+		parser.SourceNA: 0,
+		parser.TV:       1,
+		parser.SDTV:     2,
+		parser.PDTV:     3,
+		parser.DSR:      4,
+		parser.DVD:      5,
+		parser.HDTV:     6,
+		parser.WEBRip:   7,
+		parser.WEBDL:    8,
+		parser.BluRay:   9,
+	}
+)
+
+// ParseConflictPolicy parses the given string to a conflict policy.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	for p, name := range conflictPolicyNames {
+		if name == strings.ToLower(s) {
+			return p, nil
+		}
+	}
+	if s == "" {
+		return ConflictFail, nil
+	}
+	return ConflictFail, fmt.Errorf("unknown conflict policy: %s", s)
+}
+
+// String returns the string representation of p.
+func (p ConflictPolicy) String() string {
+	return conflictPolicyNames[p]
+}
+
+// resolveConflicts detects files that would end up at the same target,
+// either because the target already exists or because two files of this
+// run map to it, and applies the conflict policy before anything is moved.
+//
+// Sidecars follow their video: they take part in the detection, but are
+// never compared by quality. They only replace an existing file if their
+// video replaced one, otherwise replace-if-better skips them.
+func (r *Renamer) resolveConflicts() error {
+	var files []fileInfo
+	claimed := map[string]int{}             // target -> index in files
+	claimedBySidecar := map[string]string{} // target -> sidecar
+	taken := func(p string) bool {
+		_, ok := claimed[p]
+		_, bySidecar := claimedBySidecar[p]
+		return ok || bySidecar || exists(p)
+	}
+	var conflicts []string
+
+	for _, f := range r.files {
+		if r.skipBasedOnExtension(f.currentFilePath) {
+			files = append(files, f)
+			continue
+		}
+
+		i, inRun := claimed[f.newFilePath]
+		sidecar, bySidecar := claimedBySidecar[f.newFilePath]
+		onDisk := !inRun && !bySidecar && f.newFilePath != f.currentFilePath && exists(f.newFilePath)
+		if !inRun && !bySidecar && !onDisk {
+			claimed[f.newFilePath] = len(files)
+			files = append(files, r.resolveSidecarConflicts(f, taken, claimedBySidecar, &conflicts))
+			continue
+		}
+
+		var other string
+		switch {
+		case inRun:
+			other = files[i].currentFilePath
+		case bySidecar:
+			other = sidecar
+		default:
+			other = "existing file"
+		}
+
+		switch r.params.ConflictPolicy {
+		case ConflictFail:
+			conflicts = append(conflicts, fmt.Sprintf("%s and %s both map to %s", f.currentFilePath, other, f.newFilePath))
+		case ConflictSkip:
+			log.Warn(fmt.Sprintf("Skipping %s (%s already maps to %s)", f.currentFilePath, other, f.newFilePath))
+		case ConflictSuffix:
+			f.newFilePath = freeFilePath(f.newFilePath, taken)
+			log.Warn(fmt.Sprintf("Renaming %s to %s (%s already maps to the original target)", f.currentFilePath, f.newFilePath, other))
+			claimed[f.newFilePath] = len(files)
+			files = append(files, r.resolveSidecarConflicts(f, taken, claimedBySidecar, &conflicts))
+		case ConflictReplaceIfBetter:
+			if bySidecar {
+				log.Warn(fmt.Sprintf("Skipping %s (%s already maps to %s)", f.currentFilePath, other, f.newFilePath))
+				continue
+			}
+			var current parser.Result
+			if inRun {
+				current = files[i].parsed
+			} else {
+				var known bool
+				if current, known = existingQuality(f.newFilePath); !known {
+					log.Warn(fmt.Sprintf("Skipping %s (quality of %s at %s is unknown)", f.currentFilePath, other, f.newFilePath))
+					continue
+				}
+			}
+			if compareQuality(f.parsed, current) <= 0 {
+				log.Warn(fmt.Sprintf("Skipping %s (%s at %s is at least as good)", f.currentFilePath, other, f.newFilePath))
+				continue
+			}
+			log.Warn(fmt.Sprintf("Replacing %s at %s with better %s", other, f.newFilePath, f.currentFilePath))
+			if inRun {
+				for _, s := range files[i].sidecars {
+					delete(claimedBySidecar, s.newFilePath(files[i].newFilePath))
+				}
+				f.replaces = files[i].replaces
+				files[i] = r.resolveSidecarConflicts(f, taken, claimedBySidecar, &conflicts)
+			} else {
+				f.replaces = true
+				claimed[f.newFilePath] = len(files)
+				files = append(files, r.resolveSidecarConflicts(f, taken, claimedBySidecar, &conflicts))
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("target conflicts:\n%s", strings.Join(conflicts, "\n"))
+	}
+	r.files = files
+	return nil
+}
+
+// resolveSidecarConflicts applies the conflict policy to the sidecars of f
+// and claims their targets. Sidecars with an extension that is not moved
+// are dropped.
+func (r *Renamer) resolveSidecarConflicts(f fileInfo, taken func(string) bool, claimedBySidecar map[string]string, conflicts *[]string) fileInfo {
+	var sidecars []sidecar
+	for _, s := range f.sidecars {
+		if r.skipBasedOnExtension(s.currentFilePath) {
+			log.Warn(fmt.Sprintf("Skipping %s (based on extension)", s.currentFilePath))
+			continue
+		}
+
+		target := s.newFilePath(f.newFilePath)
+		other, inRun := claimedBySidecar[target]
+		if !inRun {
+			other = "existing file"
+		}
+		if target != s.currentFilePath && taken(target) && !(f.replaces && !inRun) {
+			switch r.params.ConflictPolicy {
+			case ConflictFail:
+				*conflicts = append(*conflicts, fmt.Sprintf("%s and %s both map to %s", s.currentFilePath, other, target))
+				continue
+			case ConflictSuffix:
+				free := freeFilePath(target, taken)
+				log.Warn(fmt.Sprintf("Renaming %s to %s (%s already maps to the original target)", s.currentFilePath, free, other))
+				s.suffix = strings.TrimPrefix(free, strings.TrimSuffix(f.newFilePath, path.Ext(f.newFilePath)))
+				target = free
+			default:
+				log.Warn(fmt.Sprintf("Skipping %s (%s already maps to %s)", s.currentFilePath, other, target))
+				continue
+			}
+		}
+		claimedBySidecar[target] = s.currentFilePath
+		sidecars = append(sidecars, s)
+	}
+	f.sidecars = sidecars
+	return f
+}
+
+// freeFilePath appends " (2)", " (3)".. to the file name until it is not taken.
+func freeFilePath(p string, taken func(string) bool) string {
+	ext := path.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// existingQuality reads the quality of the file that already exists at
+// p. Its name comes from the template, which may leave out the quality,
+// so the container headers are probed to fill that in. The quality is
+// unknown if neither tells the resolution or the source.
+func existingQuality(p string) (parser.Result, bool) {
+	pr := parser.Parse(path.Base(p), parser.Result{})
+	if info, err := probe.File(filepath.FromSlash(p)); err == nil {
+		pr = info.Result().Merge(pr)
+	}
+	return pr, pr.Resolution != parser.ResNA || pr.Source != parser.SourceNA
+}
+
+// compareQuality returns a positive number if a is of better quality than b,
+// a negative number if it is worse and 0 if both are equal.
+func compareQuality(a, b parser.Result) int {
+	if a.Resolution != b.Resolution {
+		return int(a.Resolution) - int(b.Resolution)
+	}
+	if sourceRanks[a.Source] != sourceRanks[b.Source] {
+		return sourceRanks[a.Source] - sourceRanks[b.Source]
+	}
+	if (a.Remux == parser.True) != (b.Remux == parser.True) {
+		if a.Remux == parser.True {
+			return 1
+		}
+		return -1
+	}
+	if (a.Proper == parser.True) != (b.Proper == parser.True) {
+		if a.Proper == parser.True {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+func exists(p string) bool {
+	_, err := os.Stat(filepath.FromSlash(p))
+	return err == nil
+}
@@ -0,0 +1,244 @@
+package renamer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/journal"
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/renamer"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tmdb"
+)
+
+type conflictTest struct {
+	policy        renamer.ConflictPolicy
+	existing      string
+	expectError   bool
+	expectedFiles []string
+}
+
+var conflictTests = []conflictTest{
+	{
+		policy:      renamer.ConflictFail,
+		expectError: true,
+	},
+	{
+		policy: renamer.ConflictSkip,
+		expectedFiles: []string{
+			"Movie (1999) - 1080p.Blu-ray.mkv",
+		},
+	},
+	{
+		policy: renamer.ConflictSuffix,
+		expectedFiles: []string{
+			"Movie (1999) - 1080p.Blu-ray (2).mkv",
+			"Movie (1999) - 1080p.Blu-ray.mkv",
+		},
+	},
+	{
+		policy:   renamer.ConflictSuffix,
+		existing: "Movie (1999) - 1080p.Blu-ray.mkv",
+		expectedFiles: []string{
+			"Movie (1999) - 1080p.Blu-ray (2).mkv",
+			"Movie (1999) - 1080p.Blu-ray (3).mkv",
+			"Movie (1999) - 1080p.Blu-ray.mkv",
+		},
+	},
+	{
+		policy:   renamer.ConflictSkip,
+		existing: "Movie (1999) - 1080p.Blu-ray.mkv",
+		expectedFiles: []string{
+			"Movie (1999) - 1080p.Blu-ray.mkv",
+		},
+	},
+}
+
+func TestConflicts(t *testing.T) {
+	for _, tc := range conflictTests {
+		tmp := tempDir(t)
+		defer os.RemoveAll(tmp)
+		target := runConflictTest(t, tmp, fs.ModeMove, tc.policy, tc.existing, []string{
+			"Movie.1999.1080p.BluRay-a/movie.mkv",
+			"Movie.1999.1080p.BluRay-b/movie.mkv",
+		}, tc.expectError)
+		files := listFiles(t, filepath.Join(target, "Movie (1999)"))
+		if !equal(files, tc.expectedFiles) {
+			t.Errorf("policy %s: expected %v, got %v", tc.policy, tc.expectedFiles, files)
+		}
+	}
+}
+
+func TestConflicts_ReplaceIfBetter(t *testing.T) {
+	for _, mode := range []fs.Mode{fs.ModeMove, fs.ModeCopy, fs.ModeHardlink, fs.ModeSymlink} {
+		tmp := tempDir(t)
+		defer os.RemoveAll(tmp)
+		target := runConflictTest(t, tmp, mode, renamer.ConflictReplaceIfBetter, "Movie (1999) - 720p.WEB-DL.mkv", []string{
+			"Movie.1999.1080p.WEB-DL/movie.mkv",
+			"Movie.1999.1080p.BluRay.Remux/movie.mkv",
+			"Movie.1999.1080p.BluRay/movie.mkv",
+		}, false)
+
+		// Only the remux replaces the existing file, the other two are worse.
+		data, err := ioutil.ReadFile(filepath.Join(target, "Movie (1999)", "Movie (1999) - 720p.WEB-DL.mkv"))
+		if err != nil {
+			t.Fatalf("mode %s: %v", mode, err)
+		}
+		if string(data) != "Movie.1999.1080p.BluRay.Remux/movie.mkv" {
+			t.Errorf("mode %s: expected the remux to win, got %s", mode, data)
+		}
+
+		// The replaced file is moved aside, not removed.
+		data, err = ioutil.ReadFile(filepath.Join(target, "Movie (1999)", "Movie (1999) - 720p.WEB-DL.mkv.replaced"))
+		if err != nil {
+			t.Fatalf("mode %s: %v", mode, err)
+		}
+		if string(data) != "Movie (1999) - 720p.WEB-DL.mkv" {
+			t.Errorf("mode %s: expected the replaced file to be kept, got %s", mode, data)
+		}
+	}
+}
+
+func TestConflicts_ReplaceIfBetter_UnknownQuality(t *testing.T) {
+	tmp := tempDir(t)
+	defer os.RemoveAll(tmp)
+	target := runConflictTest(t, tmp, fs.ModeMove, renamer.ConflictReplaceIfBetter, "Movie (1999).mkv", []string{
+		"Movie.1999.1080p.BluRay/movie.mkv",
+	}, false)
+
+	// Neither the name nor the headers tell the quality of the existing file.
+	files := listFiles(t, filepath.Join(target, "Movie (1999)"))
+	if expected := []string{"Movie (1999).mkv"}; !equal(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(target, "Movie (1999)", "Movie (1999).mkv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Movie (1999).mkv" {
+		t.Errorf("expected the existing file to be kept, got %s", data)
+	}
+}
+
+func TestConflicts_ReplaceIfBetter_Undo(t *testing.T) {
+	tmp := tempDir(t)
+	defer os.RemoveAll(tmp)
+	existing := filepath.Join(tmp, "movies", "Movie (1999)", "Movie (1999) - 720p.WEB-DL.mkv")
+	source := filepath.Join(tmp, "downloads", "Movie.1999.1080p.BluRay/movie.mkv")
+	mustWriteFile(t, existing, "existing")
+	mustWriteFile(t, source, "source")
+
+	params := renamer.NewParameters(filepath.Join(tmp, "downloads"), filepath.Join(tmp, "movies"), parser.Result{}, nil, false, false, false)
+	params.ConflictPolicy = renamer.ConflictReplaceIfBetter
+	params.Templates, _ = renamer.ParseTemplates(map[string]string{"movie": "{{.Name}}/Movie (1999) - 720p.WEB-DL"})
+	j := journal.New(filepath.Join(tmp, "journal"))
+	n := renamer.New(params, search.NewSearcher(
+		mockTMDBResponse([]tmdb.SearchResult{{Title: "Movie"}}),
+		mockTVDBResponse(nil),
+		mock.NewMockPrompter(nil, nil, nil),
+	), j.Wrap(fs.NewFileSystem(false)))
+	if err := n.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := journal.Load(filepath.Join(tmp, "journal"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	for p, content := range map[string]string{existing: "existing", source: "source"} {
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("expected %s at %s, got %s", content, p, data)
+		}
+	}
+}
+
+// runConflictTest moves all sources to the same target, which is
+// enforced by a template that only consists of the existing file's name.
+func runConflictTest(t *testing.T, tmp string, mode fs.Mode, policy renamer.ConflictPolicy, existing string, sources []string, expectError bool) string {
+	var err error
+	target := filepath.Join(tmp, "movies")
+	for _, s := range sources {
+		mustWriteFile(t, filepath.Join(tmp, "downloads", s), s)
+	}
+	if existing != "" {
+		mustWriteFile(t, filepath.Join(target, "Movie (1999)", existing), existing)
+	}
+
+	params := renamer.NewParameters(filepath.Join(tmp, "downloads"), target, parser.Result{}, nil, false, false, false)
+	params.Mode = mode
+	params.ConflictPolicy = policy
+	if existing != "" {
+		params.Templates, err = renamer.ParseTemplates(map[string]string{"movie": "{{.Name}}/" + existing[:len(existing)-4]})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := renamer.New(params, search.NewSearcher(
+		mockTMDBResponse([]tmdb.SearchResult{{Title: "Movie"}}),
+		mockTVDBResponse(nil),
+		mock.NewMockPrompter(nil, nil, nil),
+	), fs.NewFileSystem(false))
+
+	err = n.Run()
+	if expectError && err == nil {
+		t.Errorf("policy %s: expected an error", policy)
+	}
+	if !expectError && err != nil {
+		t.Errorf("policy %s: unexpected error: %v", policy, err)
+	}
+	return target
+}
+
+func tempDir(t *testing.T) string {
+	tmp, err := ioutil.TempDir("", "plexname-renamer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmp
+}
+
+func mustWriteFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, info := range infos {
+		files = append(files, info.Name())
+	}
+	sort.Strings(files)
+	return files
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
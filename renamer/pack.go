@@ -0,0 +1,105 @@
+package renamer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/florianehmke/plexname/log"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/search"
+)
+
+// seriesPackRegEx matches folders with several seasons,
+// e.g. Show.S01-S05.1080p or Show.Complete.Series.
+var seriesPackRegEx = regexp.MustCompile(`(?i)(^|[^a-z0-9])(complete[ ._-]?(series|seasons?)|s\d{1,2}-s?\d{1,2}|seasons?[ ._-]?\d{1,2}-\d{1,2})([^a-z0-9]|$)`)
+
+// pack is a season or series pack, a folder of episodes that share the
+// title, season, quality and language parsed once from its name.
+type pack struct {
+	dir    string
+	parsed parser.Result
+
+	// The series is searched once, for the first file of the pack.
+	searched bool
+	found    search.Result
+	err      error
+}
+
+// parseAndSearch parses a file below the source path and searches for it,
+// the files of a pack share the parsed folder name and a single search.
+func (r *Renamer) parseAndSearch(file string) (parser.Result, search.Result, error) {
+	pk := r.packFor(file)
+	if pk == nil {
+		return r.search(file, r.parse(file, r.params.TargetPath))
+	}
+
+	// The folder name stays, for names like Show S01/1 - Title.mkv.
+	pr := parser.Parse(path.Base(pk.dir)+"/"+strings.TrimPrefix(file, pk.dir+"/"), pk.parsed.Merge(r.overridesFor(file)))
+	if !pk.searched {
+		pk.found, pk.err = r.searcher.SearchTV(r.queryFor(pk.dir, pk.parsed))
+		pk.searched = true
+	}
+	if pk.err != nil {
+		return pr, pk.found, pk.err
+	}
+	return r.resolveEpisode(pr, pk.found)
+}
+
+// packFor returns the outermost pack below the source path
+// that contains file, or nil if file is not part of a pack.
+func (r *Renamer) packFor(file string) *pack {
+	if r.params.OnlyFile {
+		return nil
+	}
+	source := filepath.ToSlash(filepath.Clean(r.params.SourcePath))
+	rel, err := filepath.Rel(source, path.Dir(file))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+
+	dirs := []string{source}
+	if rel != "." {
+		for _, name := range strings.Split(filepath.ToSlash(rel), "/") {
+			dirs = append(dirs, dirs[len(dirs)-1]+"/"+name)
+		}
+	}
+	for _, dir := range dirs {
+		pk, ok := r.packs[dir]
+		if !ok {
+			pk = r.newPack(dir)
+			r.packs[dir] = pk
+		}
+		if pk != nil {
+			return pk
+		}
+	}
+	return nil
+}
+
+// newPack parses the name of dir, it returns nil if dir is no pack.
+func (r *Renamer) newPack(dir string) *pack {
+	name := path.Base(dir)
+	overrides := r.overridesFor(dir)
+	pr := parser.Parse(name, overrides)
+
+	series := seriesPackRegEx.MatchString(name)
+	season := pr.Season > 0 && pr.Episode == 0 && pr.AbsoluteEpisode == 0 && pr.AirDate.IsZero()
+	if (!series && !season) || pr.Title == "" {
+		return nil
+	}
+
+	// The files of a pack bring their own episode, and season for series packs.
+	if series && overrides.Season == 0 {
+		pr.Season = 0
+	}
+	if overrides.Episode == 0 {
+		pr.Episode, pr.LastEpisode = 0, 0
+	}
+	pr.MediaType = parser.MediaTypeTV
+
+	log.Info(fmt.Sprintf("Pack: %s", dir))
+	return &pack{dir: dir, parsed: pr}
+}
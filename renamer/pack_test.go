@@ -0,0 +1,54 @@
+package renamer_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/renamer"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tvdb"
+)
+
+type countingTVDB struct {
+	tvdb.Client
+	queries []string
+}
+
+func (c *countingTVDB) Search(query string) (*tvdb.SearchResponse, error) {
+	c.queries = append(c.queries, query)
+	return c.Client.Search(query)
+}
+
+func TestSeasonPack(t *testing.T) {
+	tmp := tempDir(t)
+	download := filepath.Join(tmp, "downloads", "Show.S02.German.1080p.BluRay.x264-GRP")
+	for _, f := range []string{"e01.mkv", "e02.mkv", "Other.Name.S02E03.mkv"} {
+		mustWriteFile(t, filepath.Join(download, f), f)
+	}
+
+	client := &countingTVDB{Client: mockTVDBResponse([]tvdb.SearchResult{{Title: "Show"}})}
+	params := renamer.NewParameters(filepath.Join(tmp, "downloads"), filepath.Join(tmp, "tv"), parser.Result{}, nil, false, false, false)
+	n := renamer.New(params, search.NewSearcher(
+		mockTMDBResponse(nil),
+		client,
+		mock.NewMockPrompter(nil, nil, nil),
+	), fs.NewFileSystem(false))
+	if err := n.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(client.queries) != 1 || client.queries[0] != "show" {
+		t.Errorf("expected a single search for the pack, got %v", client.queries)
+	}
+	expected := []string{
+		"Show - S02E01 - German.1080p.Blu-ray.mkv",
+		"Show - S02E02 - German.1080p.Blu-ray.mkv",
+		"Show - S02E03 - German.1080p.Blu-ray.mkv",
+	}
+	if files := listFiles(t, filepath.Join(tmp, "tv", "Show", "Season 02")); !equal(files, expected) {
+		t.Errorf("\nExpected: %v\nReceived: %v", expected, files)
+	}
+}
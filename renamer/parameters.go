@@ -6,18 +6,49 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/florianehmke/plexname/config"
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/log"
 	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/search"
 )
 
 type Parameters struct {
 	SourcePath string
 	TargetPath string
 	Overrides  parser.Result
+	Pin        Pin
+	Rules      Rules
 	Extensions []string
+	Templates  Templates
+
+	ConflictPolicy ConflictPolicy
+	Mode           fs.Mode
+
+	NonInteractive bool
+	Threshold      float64
+
+	NoCache  bool
+	CacheTTL time.Duration
+
+	// MetadataLanguage is the language of the titles
+	// found online, e.g. de-DE, empty for the default.
+	MetadataLanguage string
+
+	// TVProvider is searched for series first, the
+	// other one if it fails, see search.UseTVProvider.
+	TVProvider string
 
 	DryRun bool
 
+	// Probe reads the container headers of files to fill in
+	// quality fields, see probe.Info. With PreferProbe the probed
+	// fields win over the parsed ones, else they only fill gaps.
+	Probe       bool
+	PreferProbe bool
+
 	OnlyFile bool
 	OnlyDir  bool
 }
@@ -33,6 +64,7 @@ func NewParameters(source, target string, overrides parser.Result, extensions []
 		TargetPath: strings.TrimRight(filepath.ToSlash(targetPath), "/"),
 		Overrides:  overrides,
 		Extensions: extensions,
+		Templates:  DefaultTemplates(),
 		DryRun:     dryRun,
 		OnlyFile:   onlyFile,
 		OnlyDir:    onlyDir,
@@ -47,8 +79,10 @@ func GetParametersFromFlags() Parameters {
 	flag.StringVar(&overrides.Title, "title", "", "movie/tv title")
 	flag.IntVar(&overrides.Year, "year", 0, "movie/tv year of release")
 	flag.IntVar(&overrides.Season, "season", 0, "tv season of release")
-	flag.IntVar(&overrides.Episode1, "episode1", 0, "tv episode1 of release")
-	flag.IntVar(&overrides.Episode2, "episode2", 0, "tv episode2 of release")
+	flag.IntVar(&overrides.Episode, "episode", 0, "tv episode of release")
+	flag.IntVar(&overrides.LastEpisode, "last-episode", 0, "last tv episode of a multi episode release")
+	flag.IntVar(&overrides.Episode, "episode1", 0, "deprecated, use -episode")
+	flag.IntVar(&overrides.LastEpisode, "episode2", 0, "deprecated, use -last-episode")
 	var proper, remux, dualLang string
 	flag.StringVar(&proper, "proper", "", "proper release")
 	flag.StringVar(&remux, "remux", "", "remux of source, no encode")
@@ -60,13 +94,58 @@ func GetParametersFromFlags() Parameters {
 	flag.StringVar(&source, "source", "", "media source (web-dl, blu-ray etc)")
 	flag.StringVar(&lang, "lang", "", "audio language")
 
+	var videoCodec, audioCodec, audioChannels, hdr, bitDepth string
+	flag.StringVar(&videoCodec, "video-codec", "", "video codec (avc, hevc etc)")
+	flag.StringVar(&audioCodec, "audio-codec", "", "audio codec (dts, truehd etc)")
+	flag.StringVar(&audioChannels, "audio-channels", "", "audio channels (2.0, 5.1 etc)")
+	flag.StringVar(&hdr, "hdr", "", "hdr format (hdr10, dv etc)")
+	flag.StringVar(&bitDepth, "bit-depth", "", "bit depth (8bit, 10bit etc)")
+
+	pin := Pin{}
+	flag.IntVar(&pin.TMDBID, "tmdb-id", 0, "tmdb id of the movie or series, skips the search")
+	flag.IntVar(&pin.TVDBID, "tvdb-id", 0, "tvdb id of the series, skips the search")
+	flag.StringVar(&pin.IMDbID, "imdb-id", "", "imdb id of the movie or series (tt...), skips the search")
+
+	var rulesPath string
+	flag.StringVar(&rulesPath, "rules", RulesPath(), "file of per title rules, see Rule")
+
+	var metadataLanguage string
+	flag.StringVar(&metadataLanguage, "metadata-language", "", "language of titles found online, e.g. de-DE (default en-US or from the config file); with -non-interactive movies are matched by their original and translated titles too")
+
+	var tvProvider string
+	flag.StringVar(&tvProvider, "tv-provider", "", "searched for series first, the other one if it fails (tvdb|tmdb) (default tvdb or from the config file)")
+
 	var extensions string
-	flag.StringVar(&extensions, "extensions", "", "move only file with the given extension")
+	flag.StringVar(&extensions, "extensions", "", "move only file with the given extension, sidecars included")
+
+	templates := templateFlag{}
+	flag.Var(templates, "template", "naming template as kind=text, kind is movie, episode or season (repeatable)")
+
+	var conflictPolicy string
+	flag.StringVar(&conflictPolicy, "conflict", "fail", "what to do if a target exists (fail|skip|suffix|replace-if-better)")
+
+	var mode string
+	flag.StringVar(&mode, "mode", "move", "how files are transferred (move|copy|hardlink|symlink|reflink)")
+
+	var nonInteractive bool
+	var threshold float64
+	flag.BoolVar(&nonInteractive, "non-interactive", false, "never prompt, pick the best match or skip the file")
+	flag.Float64Var(&threshold, "threshold", 0.8, "minimum score (0..1) of an automatically picked match")
+
+	var noCache bool
+	var cacheTTL time.Duration
+	flag.BoolVar(&noCache, "no-cache", false, "neither read nor write the metadata cache")
+	flag.DurationVar(&cacheTTL, "cache-ttl", 7*24*time.Hour, "how long cached search responses are used")
+
+	var probeFiles, preferProbe bool
+	flag.BoolVar(&probeFiles, "probe", false, "read resolution, codec, hdr and audio languages from mkv/mp4 headers")
+	flag.BoolVar(&preferProbe, "prefer-probe", false, "like -probe, but probed fields win over the parsed ones")
 
 	var onlyDir, onlyFile bool
 	flag.BoolVar(&onlyDir, "only-dir", false, "parse only the directory name")
 	flag.BoolVar(&onlyFile, "only-file", false, "parse only file name")
 	flag.Parse()
+	warnDeprecated(map[string]string{"episode1": "episode", "episode2": "last-episode"})
 
 	overrides.Proper = boolFor(proper)
 	overrides.Remux = boolFor(remux)
@@ -76,6 +155,11 @@ func GetParametersFromFlags() Parameters {
 	overrides.Resolution = resolutionFor(resolution)
 	overrides.Source = sourceFor(source)
 	overrides.Language = languageFor(lang)
+	overrides.VideoCodec = videoCodecFor(videoCodec)
+	overrides.AudioCodec = audioCodecFor(audioCodec)
+	overrides.AudioChannels = audioChannelsFor(audioChannels)
+	overrides.HDR = hdrFor(hdr)
+	overrides.BitDepth = bitDepthFor(bitDepth)
 
 	if flag.NArg() == 0 || flag.NArg() > 2 {
 		flag.Usage()
@@ -102,8 +186,21 @@ func GetParametersFromFlags() Parameters {
 		targetPath = flag.Arg(1)
 	}
 
-	return NewParameters(sourcePath, targetPath, overrides, splitExtensions(extensions), dryRun, onlyFile, onlyDir)
-
+	params := NewParameters(sourcePath, targetPath, overrides, splitExtensions(extensions), dryRun, onlyFile, onlyDir)
+	params.Templates = templatesFor(templates)
+	params.MetadataLanguage = metadataLanguageFor(metadataLanguage)
+	params.TVProvider = tvProviderFor(tvProvider)
+	params.Pin = pin
+	params.Rules = rulesFor(rulesPath)
+	params.ConflictPolicy = conflictPolicyFor(conflictPolicy)
+	params.Mode = modeFor(mode)
+	params.NonInteractive = nonInteractive
+	params.Threshold = threshold
+	params.NoCache = noCache
+	params.CacheTTL = cacheTTL
+	params.Probe = probeFiles || preferProbe
+	params.PreferProbe = preferProbe
+	return params
 }
 
 func mediaTypeFor(s string) parser.MediaType {
@@ -142,6 +239,158 @@ func languageFor(s string) parser.Language {
 	return l
 }
 
+func videoCodecFor(s string) parser.VideoCodec {
+	c, err := parser.ParseVideoCodec(s)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return c
+}
+
+func audioCodecFor(s string) parser.AudioCodec {
+	c, err := parser.ParseAudioCodec(s)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return c
+}
+
+func audioChannelsFor(s string) parser.AudioChannels {
+	c, err := parser.ParseAudioChannels(s)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return c
+}
+
+func hdrFor(s string) parser.HDR {
+	h, err := parser.ParseHDR(s)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return h
+}
+
+func bitDepthFor(s string) parser.BitDepth {
+	d, err := parser.ParseBitDepth(s)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return d
+}
+
+// warnDeprecated warns about the use of deprecated flags,
+// keyed by their name with the name of their replacement.
+func warnDeprecated(replacements map[string]string) {
+	flag.Visit(func(f *flag.Flag) {
+		if replacement, ok := replacements[f.Name]; ok {
+			log.Warnf("-%s is deprecated, use -%s", f.Name, replacement)
+		}
+	})
+}
+
+func modeFor(s string) fs.Mode {
+	m, err := fs.ParseMode(s)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return m
+}
+
+func conflictPolicyFor(s string) ConflictPolicy {
+	p, err := ParseConflictPolicy(s)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return p
+}
+
+func rulesFor(path string) Rules {
+	rules, err := LoadRules(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return rules
+}
+
+func metadataLanguageFor(flagLanguage string) string {
+	if flagLanguage != "" {
+		return flagLanguage
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return cfg.Language
+}
+
+func tvProviderFor(flagProvider string) string {
+	if flagProvider == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		flagProvider = cfg.TVProvider
+	}
+	p, err := search.ParseTVProvider(flagProvider)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return p
+}
+
+func templatesFor(flagTemplates templateFlag) Templates {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	texts := map[string]string{}
+	for kind, text := range cfg.Templates {
+		texts[kind] = text
+	}
+	for kind, text := range flagTemplates {
+		texts[kind] = text
+	}
+	t, err := ParseTemplates(texts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return t
+}
+
+// templateFlag collects -template kind=text flags.
+type templateFlag map[string]string
+
+func (f templateFlag) String() string {
+	var s []string
+	for kind, text := range f {
+		s = append(s, kind+"="+text)
+	}
+	return strings.Join(s, ",")
+}
+
+func (f templateFlag) Set(value string) error {
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("expected kind=text, got %s", value)
+	}
+	f[kv[0]] = kv[1]
+	return nil
+}
+
 func boolFor(s string) parser.ParseBool {
 	ls := strings.ToLower(s)
 	if ls == "true" {
@@ -1,6 +1,7 @@
 package renamer_test
 
 import (
+	"flag"
 	"os"
 	"strings"
 	"testing"
@@ -17,8 +18,8 @@ func TestGetParametersFromFlags(t *testing.T) {
 		"plexname",
 		"-dry",
 		"-dl", "true",
-		"-episode1", "5",
-		"-episode2", "6",
+		"-episode", "5",
+		"-last-episode", "6",
 		"-extensions", "mkv,avi",
 		"-lang", "german",
 		"-media-type", "tv",
@@ -29,6 +30,11 @@ func TestGetParametersFromFlags(t *testing.T) {
 		"-source", "bluray",
 		"-title", "Some Title",
 		"-year", "1999",
+		"-video-codec", "hevc",
+		"-audio-codec", "truehd",
+		"-audio-channels", "7.1",
+		"-hdr", "dv",
+		"-bit-depth", "10bit",
 		"-only-dir",
 		"-only-file",
 		"some/path",
@@ -56,8 +62,8 @@ func TestGetParametersFromFlags(t *testing.T) {
 	expectedOverrides := parser.Result{
 		Title:        "Some Title",
 		DualLanguage: parser.True,
-		Episode1:     5,
-		Episode2:     6,
+		Episode:      5,
+		LastEpisode:  6,
 		Language:     parser.German,
 		MediaType:    parser.MediaTypeTV,
 		Proper:       parser.True,
@@ -66,8 +72,26 @@ func TestGetParametersFromFlags(t *testing.T) {
 		Season:       3,
 		Source:       parser.BluRay,
 		Year:         1999,
+
+		VideoCodec:    parser.H265,
+		AudioCodec:    parser.TrueHD,
+		AudioChannels: parser.Surround71,
+		HDR:           parser.DolbyVision,
+		BitDepth:      parser.Bit10,
 	}
 	if args.Overrides != expectedOverrides {
 		t.Error("expected different overrides")
 	}
 }
+
+func TestGetParametersFromFlags_DeprecatedEpisodes(t *testing.T) {
+	oldArgs, oldFlags := os.Args, flag.CommandLine
+	defer func() { os.Args, flag.CommandLine = oldArgs, oldFlags }()
+	flag.CommandLine = flag.NewFlagSet("plexname", flag.ExitOnError)
+
+	os.Args = []string{"plexname", "-episode1", "5", "-episode2", "6", "some/path"}
+	args := renamer.GetParametersFromFlags()
+	if args.Overrides.Episode != 5 || args.Overrides.LastEpisode != 6 {
+		t.Errorf("expected episodes 5-6, got %d-%d", args.Overrides.Episode, args.Overrides.LastEpisode)
+	}
+}
@@ -0,0 +1,99 @@
+package renamer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/search"
+)
+
+// Pin holds the provider ids a media is pinned to, instead of searching
+// for its title the details are looked up by id. Unset ids are zero.
+type Pin struct {
+	TMDBID int    // movies and series
+	TVDBID int    // series
+	IMDbID string // movies and series, e.g. tt0111161
+}
+
+// idHintRegEx matches the ids plex and others recognise in
+// folder names, e.g. {tmdb-603}, [imdbid-tt0111161] or tvdb-78804.
+var idHintRegEx = regexp.MustCompile(`(?i)\b(tmdb|tvdb|imdb)(?:id)?-(tt\d+|\d+)\b`)
+
+// merge returns p with the ids that are set in other.
+func (p Pin) merge(other Pin) Pin {
+	if other.TMDBID != 0 {
+		p.TMDBID = other.TMDBID
+	}
+	if other.TVDBID != 0 {
+		p.TVDBID = other.TVDBID
+	}
+	if other.IMDbID != "" {
+		p.IMDbID = other.IMDbID
+	}
+	return p
+}
+
+// idHints returns the ids found in the names of file and its folders,
+// of several hints for the same provider the innermost one wins.
+func idHints(file string) Pin {
+	var pin Pin
+	for _, name := range strings.Split(file, "/") {
+		for _, m := range idHintRegEx.FindAllStringSubmatch(name, -1) {
+			provider, id := strings.ToLower(m[1]), strings.ToLower(m[2])
+			n, err := strconv.Atoi(id)
+			switch {
+			case provider == "imdb" && strings.HasPrefix(id, "tt"):
+				pin.IMDbID = id
+			case provider == "tmdb" && err == nil:
+				pin.TMDBID = n
+			case provider == "tvdb" && err == nil:
+				pin.TVDBID = n
+			}
+		}
+	}
+	return pin
+}
+
+// pinFor returns the ids file is pinned to. The flags win
+// over the rules and the rules over hints in the folder names.
+func (r *Renamer) pinFor(file string) Pin {
+	_, pin := r.params.Rules.match(file)
+	return idHints(file).merge(pin).merge(r.params.Pin)
+}
+
+// queryFor returns the search query for pr, pinned to the ids for file.
+func (r *Renamer) queryFor(file string, pr parser.Result) search.Query {
+	query := search.Query{Title: pr.Title, Year: pr.Year}
+	pin := r.pinFor(file)
+	if pr.IsMovie() {
+		query.ID = pin.TMDBID
+		query.IMDbID = pin.IMDbID
+	} else if pr.IsTV() {
+		query.ID = pin.TVDBID
+		if query.ID == 0 && pin.TMDBID != 0 {
+			query.ID, query.Provider = pin.TMDBID, search.ProviderTMDB
+		}
+		query.IMDbID = pin.IMDbID
+	}
+	return query
+}
+
+// idTag returns the id of a pinned result as plex recognises
+// it in folder names, e.g. {tmdb-603} or {tvdb-78804}.
+func idTag(pr parser.Result, sr search.Result) string {
+	if !sr.Pinned || sr.ID == 0 {
+		return ""
+	}
+	if pr.IsMovie() {
+		return "{tmdb-" + strconv.Itoa(sr.ID) + "}"
+	}
+	if pr.IsTV() && sr.Provider == search.ProviderTMDB {
+		return "{tmdb-" + strconv.Itoa(sr.ID) + "}"
+	}
+	if pr.IsTV() {
+		return "{tvdb-" + strconv.Itoa(sr.ID) + "}"
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package renamer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/renamer"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tmdb"
+)
+
+func TestPin(t *testing.T) {
+	expected := "/dev/null/The Shawshank Redemption (1994) {tmdb-278}/The Shawshank Redemption (1994) - German.1080p.DL.Blu-ray.Remux.mkv"
+	mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
+		if newPath != expected {
+			t.Errorf("\nExpected: %s\nReceived: %s", expected, newPath)
+		}
+		return nil
+	}, func(path string) error { return nil })
+	mockedPrompter := mock.NewMockPrompter(func(question string) (int, error) {
+		t.Errorf("expected no prompt, got %s", question)
+		return 0, nil
+	}, nil, nil)
+
+	params := renamer.NewParameters("../tests/fixtures/movie-parse-from-file", "/dev/null", parser.Result{}, []string{}, false, false, false)
+	params.Pin = renamer.Pin{IMDbID: "tt0111161"}
+	n := renamer.New(params, search.NewSearcher(
+		mockTMDBResponse([]tmdb.SearchResult{{ID: 278, Title: "The Shawshank Redemption", ReleaseDate: "1994-09-23"}}),
+		mockTVDBResponse(nil),
+		mockedPrompter,
+	), mockedFS)
+	if err := n.Run(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPin_TMDBSeries(t *testing.T) {
+	tmp := tempDir(t)
+	defer os.RemoveAll(tmp)
+	mustWriteFile(t, filepath.Join(tmp, "Doctor Who {tmdb-57243}", "doctor.who.s01e01.1080p.mkv"), "")
+
+	expected := "/dev/null/Doctor Who (2005) {tmdb-57243}/Season 01/Doctor Who (2005) - S01E01 - Rose - 1080p.mkv"
+	mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
+		if newPath != expected {
+			t.Errorf("\nExpected: %s\nReceived: %s", expected, newPath)
+		}
+		return nil
+	}, func(path string) error { return nil })
+
+	params := renamer.NewParameters(tmp, "/dev/null", parser.Result{}, []string{}, false, false, false)
+	n := renamer.New(params, search.NewSearcher(
+		mock.NewMockTMDBWithTV(tmdb.TVSearchResponse{Results: []tmdb.TVSearchResult{
+			{ID: 57243, Name: "Doctor Who", FirstAirDate: "2005-03-26"},
+		}}, []tmdb.Season{{SeasonNumber: 1, Episodes: []tmdb.Episode{
+			{SeasonNumber: 1, EpisodeNumber: 1, Name: "Rose"},
+		}}}, nil),
+		mockTVDBResponse(nil),
+		mock.NewMockPrompter(nil, nil, nil),
+	), mockedFS)
+	if err := n.Run(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPin_SingleFile(t *testing.T) {
+	tmp := tempDir(t)
+	defer os.RemoveAll(tmp)
+	source := filepath.Join(tmp, "Shawshank {tmdb-278}", "shawshank.1994.1080p.mkv")
+	mustWriteFile(t, source, "")
+
+	expected := filepath.ToSlash(filepath.Dir(source)) + "/The Shawshank Redemption (1994) - 1080p.mkv"
+	mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
+		if newPath != expected {
+			t.Errorf("\nExpected: %s\nReceived: %s", expected, newPath)
+		}
+		return nil
+	}, func(path string) error { return nil })
+	mockedPrompter := mock.NewMockPrompter(func(question string) (int, error) {
+		t.Errorf("expected no prompt, got %s", question)
+		return 0, nil
+	}, nil, nil)
+
+	params := renamer.NewParameters(source, "", parser.Result{}, []string{}, false, false, false)
+	n := renamer.New(params, search.NewSearcher(
+		mockTMDBResponse([]tmdb.SearchResult{
+			{ID: 1, Title: "Shawshank", ReleaseDate: "1994-01-01"},
+			{ID: 278, Title: "The Shawshank Redemption", ReleaseDate: "1994-09-23"},
+		}),
+		mockTVDBResponse(nil),
+		mockedPrompter,
+	), mockedFS)
+	if err := n.Run(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,158 @@
+package renamer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/search"
+)
+
+// Plan is the reviewable list of moves a run would make.
+//
+// Mode and ConflictPolicy are those of the planning run, apply uses
+// them too. Plans without them are applied as moves that fail on conflicts.
+type Plan struct {
+	Created        time.Time  `json:"created"`
+	Mode           string     `json:"mode,omitempty"`
+	ConflictPolicy string     `json:"conflict_policy,omitempty"`
+	Items          []PlanItem `json:"items"`
+}
+
+// PlanItem is a single planned move.
+//
+// Source and Target are the only fields apply acts on, Parsed and Match
+// are there to help with reviewing. Size and ModTime of the source are
+// used to detect changes between planning and applying.
+type PlanItem struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+
+	Parsed parser.Result `json:"parsed"`
+	Match  search.Result `json:"match"`
+}
+
+// Plan determines the new paths of all files below the source path without moving anything.
+func (r *Renamer) Plan() (Plan, error) {
+	if err := r.collect(); err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{
+		Created:        time.Now(),
+		Mode:           r.params.Mode.String(),
+		ConflictPolicy: r.params.ConflictPolicy.String(),
+		Items:          []PlanItem{},
+	}
+	for _, f := range r.files {
+		if r.skipBasedOnExtension(f.currentFilePath) {
+			continue
+		}
+		info, err := os.Stat(filepath.FromSlash(f.currentFilePath))
+		if err != nil {
+			return Plan{}, fmt.Errorf("stat of %s failed: %v", f.currentFilePath, err)
+		}
+		plan.Items = append(plan.Items, PlanItem{
+			Source:  f.currentFilePath,
+			Target:  f.newFilePath,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Parsed:  f.parsed,
+			Match:   f.found,
+		})
+		for _, s := range f.sidecars {
+			info, err := os.Stat(filepath.FromSlash(s.currentFilePath))
+			if err != nil {
+				return Plan{}, fmt.Errorf("stat of %s failed: %v", s.currentFilePath, err)
+			}
+			plan.Items = append(plan.Items, PlanItem{
+				Source:  s.currentFilePath,
+				Target:  s.newFilePath(f.newFilePath),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+				Parsed:  f.parsed,
+				Match:   f.found,
+			})
+		}
+	}
+	return plan, nil
+}
+
+// Apply runs exactly the moves of the given plan, with the mode and
+// conflict policy of the plan.
+//
+// Nothing is moved if any source changed since planning.
+func (r *Renamer) Apply(plan Plan) error {
+	mode, err := fs.ParseMode(plan.Mode)
+	if err != nil {
+		return fmt.Errorf("invalid plan: %v", err)
+	}
+	policy, err := ParseConflictPolicy(plan.ConflictPolicy)
+	if err != nil {
+		return fmt.Errorf("invalid plan: %v", err)
+	}
+	r.params.Mode = mode
+	r.params.ConflictPolicy = policy
+
+	for _, item := range plan.Items {
+		info, err := os.Stat(filepath.FromSlash(item.Source))
+		if err != nil {
+			return fmt.Errorf("stat of %s failed: %v", item.Source, err)
+		}
+		if info.Size() != item.Size || !info.ModTime().Equal(item.ModTime) {
+			return fmt.Errorf("%s changed since planning", item.Source)
+		}
+		if item.Target == "" {
+			return fmt.Errorf("no target for %s", item.Source)
+		}
+	}
+
+	r.files = []fileInfo{}
+	for _, item := range plan.Items {
+		r.files = append(r.files, fileInfo{
+			currentFilePath: item.Source,
+			parsed:          item.Parsed,
+			found:           item.Match,
+			newPath:         path.Dir(item.Target),
+			newFilePath:     item.Target,
+		})
+	}
+	if err := r.resolveConflicts(); err != nil {
+		return err
+	}
+	return r.moveAndRename()
+}
+
+// ReadPlan reads a plan file.
+func ReadPlan(file string) (Plan, error) {
+	var plan Plan
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return plan, fmt.Errorf("could not read plan: %v", err)
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("could not parse plan: %v", err)
+	}
+	return plan, nil
+}
+
+// WritePlan writes plan to a file.
+func WritePlan(file string, plan Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal of plan failed: %v", err)
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("could not write plan: %v", err)
+	}
+	return nil
+}
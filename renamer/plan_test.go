@@ -0,0 +1,90 @@
+package renamer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/renamer"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tmdb"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "plexname-plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	source := filepath.Join(tmp, "downloads", "Movie.Title.1999.German.1080p.DL.BluRay-group", "movie.mkv")
+	if err := os.MkdirAll(filepath.Dir(source), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(source, []byte("movie"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	params := renamer.NewParameters(filepath.Join(tmp, "downloads"), filepath.Join(tmp, "movies"), parser.Result{}, nil, false, false, false)
+	searcher := search.NewSearcher(
+		mockTMDBResponse([]tmdb.SearchResult{{Title: "Real Movie Title"}}),
+		mockTVDBResponse(nil),
+		mock.NewMockPrompter(nil, nil, nil),
+	)
+	params.Mode = fs.ModeCopy
+	plan, err := renamer.New(params, searcher, fs.NewFileSystem(true)).Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Items) != 1 {
+		t.Fatalf("expected 1 planned move, got %d", len(plan.Items))
+	}
+	expectedTarget := filepath.ToSlash(filepath.Join(tmp, "movies", "Real Movie Title (1999)", "Real Movie Title (1999) - German.1080p.DL.Blu-ray.mkv"))
+	if plan.Items[0].Target != expectedTarget {
+		t.Errorf("\nExpected: %s\nReceived: %s", expectedTarget, plan.Items[0].Target)
+	}
+	if plan.Items[0].Match.Title != "Real Movie Title" {
+		t.Errorf("expected the search match in the plan")
+	}
+
+	// Edit the plan file, as a reviewer would.
+	planFile := filepath.Join(tmp, "plan.json")
+	edited := filepath.Join(tmp, "movies", "Edited (1999)", "Edited (1999).mkv")
+	plan.Items[0].Target = filepath.ToSlash(edited)
+	if err := renamer.WritePlan(planFile, plan); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = renamer.ReadPlan(planFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A changed source must not be moved.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(source, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := renamer.New(renamer.Parameters{}, nil, fs.NewFileSystem(false)).Apply(plan); err == nil {
+		t.Error("expected an error for a changed source")
+	}
+	if _, err := os.Stat(source); err != nil {
+		t.Error("expected source to be left alone")
+	}
+
+	plan.Items[0].ModTime = later
+	if err := renamer.New(renamer.Parameters{}, nil, fs.NewFileSystem(false)).Apply(plan); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(edited); err != nil {
+		t.Errorf("expected %s to exist", edited)
+	}
+	// The plan was made in copy mode, so apply copies too.
+	if _, err := os.Stat(source); err != nil {
+		t.Error("expected source to be kept by a copy plan")
+	}
+}
@@ -3,6 +3,7 @@ package renamer
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/florianehmke/plexname/fs"
 	"github.com/florianehmke/plexname/log"
 	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/probe"
 	"github.com/florianehmke/plexname/search"
 )
 
@@ -21,6 +23,12 @@ type Renamer struct {
 	fs       fs.FileSystem
 
 	files []fileInfo
+
+	// packs by folder, nil for folders that are no pack.
+	packs map[string]*pack
+
+	// unresolved files are skipped, their search had no good enough match.
+	unresolved []string
 }
 
 func New(args Parameters, searcher search.Searcher, fs fs.FileSystem) *Renamer {
@@ -29,14 +37,23 @@ func New(args Parameters, searcher search.Searcher, fs fs.FileSystem) *Renamer {
 		searcher: searcher,
 		fs:       fs,
 		files:    []fileInfo{},
+		packs:    map[string]*pack{},
 	}
 }
 
 type fileInfo struct {
 	currentFilePath string
 
+	parsed parser.Result
+	found  search.Result
+
 	newPath     string
 	newFilePath string
+
+	// replaces an existing file at newFilePath, see ConflictReplaceIfBetter.
+	replaces bool
+
+	sidecars []sidecar
 }
 
 func (r *Renamer) parse(source, target string) parser.Result {
@@ -55,31 +72,43 @@ func (r *Renamer) parse(source, target string) parser.Result {
 		toParse = srcDirAndFile
 	}
 
-	return parser.Parse(toParse, r.params.Overrides)
+	return parser.Parse(toParse, r.overridesFor(source))
 }
 
 func (r *Renamer) Run() error {
-	if info, err := os.Stat(r.params.SourcePath); err == nil {
-		if info.IsDir() {
-			return r.runDir()
-		} else {
-			return r.runFile()
-		}
+	if err := r.collect(); err != nil {
+		return err
+	}
+	if err := r.moveAndRename(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// collect determines the new paths of all files below the source path.
+func (r *Renamer) collect() error {
+	info, err := os.Stat(r.params.SourcePath)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		err = r.collectDir()
 	} else {
+		err = r.collectFile()
+	}
+	if err != nil {
 		return err
 	}
+	return r.resolveConflicts()
 }
 
-func (r *Renamer) runDir() error {
+func (r *Renamer) collectDir() error {
 	if err := r.collectFiles(); err != nil {
 		return err
 	}
 	if err := r.collectNewPaths(); err != nil {
 		return err
 	}
-	if err := r.moveAndRename(); err != nil {
-		return err
-	}
 	return nil
 }
 
@@ -93,6 +122,7 @@ func (r *Renamer) collectFiles() error {
 	}); err != nil {
 		return fmt.Errorf("directory scan failed: %v", err)
 	}
+	r.files = groupSidecars(r.files)
 	return nil
 }
 
@@ -100,29 +130,35 @@ func (r *Renamer) collectNewPaths() error {
 	var files []fileInfo
 	for _, f := range r.files {
 		log.Info(fmt.Sprintf("Processing: %s", f.currentFilePath))
-		pr := r.parse(f.currentFilePath, r.params.TargetPath)
-
-		sr, err := r.search(pr)
+		pr, sr, err := r.parseAndSearch(f.currentFilePath)
+		if ue, ok := err.(*search.UnresolvedError); ok {
+			r.skipUnresolved(f.currentFilePath, ue)
+			continue
+		}
 		if err != nil {
 			return fmt.Errorf("search for %s failed: %v", f.currentFilePath, err)
 		}
 
-		plexName, err := plexName(pr, sr)
+		pr, info := r.probeFile(f.currentFilePath, pr)
+		data, err := r.templateData(pr, sr)
 		if err != nil {
 			return fmt.Errorf("could not get a plex name for %s: %v", f.currentFilePath, err)
 		}
+		data.Probe = info
 
-		newPath, err := newDirectoryPath(r.params.TargetPath, plexName, pr)
+		newPath, err := r.newDirectoryPath(r.params.TargetPath, data)
 		if err != nil {
 			return fmt.Errorf("could not create directory path for %s: %v", f.currentFilePath, err)
 		}
 		f.newPath = newPath
 
-		newFilePath, err := newFilePath(newPath, f.fileName(), plexName, pr)
+		newFilePath, err := r.newFilePath(newPath, f.fileName(), data)
 		if err != nil {
 			return fmt.Errorf("could not create file path for %s: %v", f.currentFilePath, err)
 		}
 		f.newFilePath = newFilePath
+		f.parsed = pr
+		f.found = sr
 
 		files = append(files, f)
 	}
@@ -132,42 +168,173 @@ func (r *Renamer) collectNewPaths() error {
 
 func (r *Renamer) moveAndRename() error {
 	for _, f := range r.files {
+		if r.skipBasedOnExtension(f.currentFilePath) {
+			log.Warn(fmt.Sprintf("Skipping %s (based on extension)", f.currentFilePath))
+			continue
+		}
+		if f.replaces {
+			if err := r.moveAside(f.newFilePath); err != nil {
+				return err
+			}
+		}
 		if err := r.move(f.currentFilePath, f.newFilePath); err != nil {
 			return err
 		}
+		for _, s := range f.sidecars {
+			target := s.newFilePath(f.newFilePath)
+			if f.replaces && exists(target) {
+				if err := r.moveAside(target); err != nil {
+					return err
+				}
+			}
+			if err := r.move(s.currentFilePath, target); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
-func (r *Renamer) runFile() error {
+// moveAside renames the file at p that gets replaced to p.replaced, or
+// p.replaced.2 and so on if that exists. Unlike a removal, the rename is
+// journaled and the replaced file comes back with an undo.
+func (r *Renamer) moveAside(p string) error {
+	aside := p + ".replaced"
+	for n := 2; exists(aside); n++ {
+		aside = fmt.Sprintf("%s.replaced.%d", p, n)
+	}
+	if err := r.fs.Rename(filepath.FromSlash(p), filepath.FromSlash(aside)); err != nil {
+		return fmt.Errorf("moving aside replaced %s failed: %v", p, err)
+	}
+	log.Info(fmt.Sprintf("Moved aside replaced %s to %s", p, aside))
+	return nil
+}
+
+func (r *Renamer) collectFile() error {
 	log.Info(fmt.Sprintf("Processing: %s", r.params.SourcePath))
 	dir, file := filepath.Split(r.params.SourcePath)
 
-	pr := r.parse(file, file)
+	// Only the file name is parsed, the folder hints and the rules
+	// are looked up for the whole path like in a directory run.
+	pr := parser.Parse(file, r.overridesFor(r.params.SourcePath))
 
-	sr, err := r.search(pr)
+	pr, sr, err := r.search(r.params.SourcePath, pr)
+	if ue, ok := err.(*search.UnresolvedError); ok {
+		r.skipUnresolved(r.params.SourcePath, ue)
+		r.files = []fileInfo{}
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("search for %s failed: %v", file, err)
 	}
 
-	plexName, err := plexName(pr, sr)
+	pr, info := r.probeFile(r.params.SourcePath, pr)
+	data, err := r.templateData(pr, sr)
 	if err != nil {
 		return fmt.Errorf("could not get a plex name for %s: %v", r.params.SourcePath, err)
 	}
+	data.Probe = info
 
-	newFilePath, err := newFilePath(dir, file, plexName, pr)
+	newFilePath, err := r.newFilePath(dir, file, data)
 	if err != nil {
 		return fmt.Errorf("could not create file path for %s: %v", r.params.SourcePath, err)
 	}
 
-	return r.move(r.params.SourcePath, newFilePath)
+	r.files = []fileInfo{{
+		currentFilePath: r.params.SourcePath,
+		parsed:          pr,
+		found:           sr,
+		newPath:         dir,
+		newFilePath:     newFilePath,
+		sidecars:        r.siblingSidecars(),
+	}}
+	return nil
 }
 
-func plexName(pr parser.Result, sr search.Result) (string, error) {
-	year := pr.Year
-	if year == 0 {
-		year = sr.Year
+// probeFile fills in the quality fields of pr from the container
+// headers of the file at path, if probing is enabled. Files that are
+// no Matroska or MP4 container are left as they are.
+func (r *Renamer) probeFile(path string, pr parser.Result) (parser.Result, probe.Info) {
+	if !r.params.Probe {
+		return pr, probe.Info{}
+	}
+	// Like the walk over the source, probing reads the real file and does
+	// not go through r.fs, which only does the changes and is a no-op
+	// for dry runs.
+	info, err := probe.File(filepath.FromSlash(path))
+	if err == probe.ErrUnknownFormat {
+		return pr, info
+	}
+	if err != nil {
+		log.Warn(fmt.Sprintf("Probing %s failed: %v", path, err))
+		return pr, probe.Info{}
+	}
+	if r.params.PreferProbe {
+		return pr.Merge(info.Result()).Merge(r.overridesFor(path)), info
+	}
+	return info.Result().Merge(pr), info
+}
+
+// siblingSidecars finds the sidecars of the single source file,
+// these are the files next to it that start with its base name.
+func (r *Renamer) siblingSidecars() []sidecar {
+	dir := path.Dir(r.params.SourcePath)
+	entries, err := ioutil.ReadDir(filepath.FromSlash(dir))
+	if err != nil {
+		return nil
+	}
+	files := []fileInfo{{currentFilePath: r.params.SourcePath}}
+	for _, e := range entries {
+		p := dir + "/" + e.Name()
+		if !e.IsDir() && p != r.params.SourcePath && isSidecar(p) {
+			files = append(files, fileInfo{currentFilePath: p})
+		}
+	}
+	var sidecars []sidecar
+	for _, s := range groupSidecars(files)[0].sidecars {
+		if strings.HasPrefix(path.Base(s.currentFilePath), baseName(r.params.SourcePath)+".") {
+			sidecars = append(sidecars, s)
+		}
+	}
+	return sidecars
+}
+
+// templateData collects the template data for the given results,
+// for episodes this includes looking up their titles.
+func (r *Renamer) templateData(pr parser.Result, sr search.Result) (templateData, error) {
+	data, err := newTemplateData(pr, sr)
+	if err != nil || !pr.IsTV() {
+		return data, err
+	}
+	var titles []string
+	for _, ep := range pr.Episodes() {
+		if ep == 0 {
+			continue
+		}
+		title, err := r.searcher.EpisodeTitle(sr, pr.Season, ep)
+		if err != nil {
+			log.Warn(fmt.Sprintf("No title for %s S%02dE%02d: %v", sr.Title, pr.Season, ep, err))
+			return data, nil
+		}
+		titles = append(titles, title)
 	}
+	data.EpisodeTitle = joinNonEmpty(" & ", titles...)
+	return data, nil
+}
+
+func (r *Renamer) skipUnresolved(path string, err *search.UnresolvedError) {
+	log.Warn(fmt.Sprintf("Skipping %s (%v)", path, err))
+	r.unresolved = append(r.unresolved, path)
+}
+
+// Unresolved returns the files that were skipped because
+// a non-interactive search found no good enough match.
+func (r *Renamer) Unresolved() []string {
+	return r.unresolved
+}
+
+func plexName(pr parser.Result, sr search.Result) (string, error) {
+	year := mediaYear(pr, sr)
 	if year == 0 {
 		if pr.IsMovie() {
 			return "", errors.New("neither parser nor search yielded a year")
@@ -180,41 +347,94 @@ func plexName(pr parser.Result, sr search.Result) (string, error) {
 	return fmt.Sprintf("%s (%d)", sr.Title, year), nil
 }
 
-func newFilePath(base string, oldFileName string, plexName string, pr parser.Result) (string, error) {
+// mediaYear returns the parsed year, unless the result was looked up by
+// id, then the canonical year of the provider wins if it has one.
+func mediaYear(pr parser.Result, sr search.Result) int {
+	if pr.Year == 0 || (sr.Pinned && sr.Year != 0) {
+		return sr.Year
+	}
+	return pr.Year
+}
+
+func (r *Renamer) newFilePath(base string, oldFileName string, data templateData) (string, error) {
 	base = strings.TrimRight(base, "/")
 	extension := strings.ToLower(filepath.Ext(oldFileName))
-	versionInfo := versionInfo(pr)
-	if pr.IsTV() {
-		tvInfo := tvInfo(pr)
-		fileName := joinNonEmpty(" - ", plexName, tvInfo, versionInfo)
+	if data.Parsed.IsTV() {
+		fileName, err := execute(r.params.Templates.Episode, data)
+		if err != nil {
+			return "", fmt.Errorf("episode template failed: %v", err)
+		}
 		return base + "/" + fileName + extension, nil
 	}
-	if pr.IsMovie() {
-		fileName := joinNonEmpty(" - ", plexName, versionInfo)
-		return base + "/" + fileName + extension, nil
+	if data.Parsed.IsMovie() {
+		moviePath, err := execute(r.params.Templates.Movie, data)
+		if err != nil {
+			return "", fmt.Errorf("movie template failed: %v", err)
+		}
+		return base + "/" + path.Base(moviePath) + extension, nil
 	}
 	return "", errors.New("can't create file path for unknown media type")
 }
 
-func newDirectoryPath(base string, plexName string, pr parser.Result) (string, error) {
+func (r *Renamer) newDirectoryPath(base string, data templateData) (string, error) {
 	base = strings.TrimRight(base, "/")
-	if pr.IsTV() {
-		return fmt.Sprintf("%s/%s/Season %02d", base, plexName, pr.Season), nil
+	if data.Parsed.IsTV() {
+		seasonPath, err := execute(r.params.Templates.Season, data)
+		if err != nil {
+			return "", fmt.Errorf("season template failed: %v", err)
+		}
+		return base + "/" + seasonPath, nil
 	}
-	if pr.IsMovie() {
-		return base + "/" + plexName, nil
+	if data.Parsed.IsMovie() {
+		moviePath, err := execute(r.params.Templates.Movie, data)
+		if err != nil {
+			return "", fmt.Errorf("movie template failed: %v", err)
+		}
+		if dir := path.Dir(moviePath); dir != "." {
+			return base + "/" + dir, nil
+		}
+		return base, nil
 	}
 	return "", errors.New("can't create directory path for unknown media type")
 }
 
-func (r *Renamer) search(pr parser.Result) (search.Result, error) {
+// search looks up the media parsed from file, see resolveEpisode for episodes.
+func (r *Renamer) search(file string, pr parser.Result) (parser.Result, search.Result, error) {
 	if pr.IsMovie() {
-		return r.searcher.SearchMovie(search.Query{Title: pr.Title, Year: pr.Year})
+		sr, err := r.searcher.SearchMovie(r.queryFor(file, pr))
+		return pr, sr, err
 	}
 	if pr.IsTV() {
-		return r.searcher.SearchTV(search.Query{Title: pr.Title, Year: pr.Year})
+		sr, err := r.searcher.SearchTV(r.queryFor(file, pr))
+		if err != nil {
+			return pr, sr, err
+		}
+		return r.resolveEpisode(pr, sr)
 	}
-	return search.Result{}, errors.New("can not search for unknown media type")
+	return pr, search.Result{}, errors.New("can not search for unknown media type")
+}
+
+// resolveEpisode maps the absolute number of anime style episodes and
+// the air date of daily shows to a season and episode of the series.
+func (r *Renamer) resolveEpisode(pr parser.Result, sr search.Result) (parser.Result, search.Result, error) {
+	var err error
+	if pr.Season > 0 {
+		return pr, sr, nil
+	}
+	if pr.AbsoluteEpisode > 0 {
+		pr.Season, pr.Episode, err = r.searcher.AbsoluteEpisode(sr, pr.AbsoluteEpisode)
+		return pr, sr, err
+	}
+	if !pr.AirDate.IsZero() {
+		pr.Season, pr.Episode, err = r.searcher.AiredEpisode(sr, pr.AirDate)
+		if _, ok := err.(*search.UnresolvedError); ok {
+			// Plex names daily shows by date, with a season per year.
+			pr.Season, pr.Episode = pr.AirDate.Year(), 0
+			return pr, sr, nil
+		}
+		return pr, sr, err
+	}
+	return pr, sr, nil
 }
 
 func (fi *fileInfo) fileName() string {
@@ -223,11 +443,6 @@ func (fi *fileInfo) fileName() string {
 }
 
 func (r *Renamer) move(source, target string) error {
-	if r.skipBasedOnExtension(source) {
-		log.Warn(fmt.Sprintf("Skipping %s (based on extension)", source))
-		return nil
-	}
-
 	newDir, fileName := filepath.Split(target)
 
 	osNewDir := filepath.FromSlash(newDir)
@@ -237,11 +452,15 @@ func (r *Renamer) move(source, target string) error {
 
 	osTarget := filepath.FromSlash(target)
 	osSource := filepath.FromSlash(source)
-	if err := r.fs.Rename(osSource, osTarget); err != nil {
-		return fmt.Errorf("move of %s to %s failed: %v", fileName, osNewDir, err)
+	if err := fs.Transfer(r.fs, r.params.Mode, osSource, osTarget); err != nil {
+		return fmt.Errorf("%s of %s to %s failed: %v", r.params.Mode, fileName, osNewDir, err)
 	}
 
-	log.Info(fmt.Sprintf("Renamed:\nSource: %s\nTarget: %s", osSource, osTarget))
+	if r.params.Mode == fs.ModeMove {
+		log.Info(fmt.Sprintf("Renamed:\nSource: %s\nTarget: %s", osSource, osTarget))
+	} else {
+		log.Info(fmt.Sprintf("Renamed (%s):\nSource: %s\nTarget: %s", r.params.Mode, osSource, osTarget))
+	}
 	return nil
 }
 
@@ -266,6 +485,14 @@ func toEpisodeString(ep int) string {
 	return ""
 }
 
+// toEpisodeRangeString formats multiple episodes like plex does, e.g. E01-E03.
+func toEpisodeRangeString(first, last int) string {
+	if last > first {
+		return toEpisodeString(first) + "-" + toEpisodeString(last)
+	}
+	return toEpisodeString(first)
+}
+
 func toSeasonString(s int, special parser.ParseBool) string {
 	if s > 0 || special == parser.True {
 		return fmt.Sprintf("S%02d", s)
@@ -284,10 +511,12 @@ func joinNonEmpty(sep string, slist ...string) string {
 }
 
 func tvInfo(pr parser.Result) string {
+	if !pr.AirDate.IsZero() && pr.Episode == 0 {
+		return pr.AirDate.Format("2006-01-02")
+	}
 	return joinNonEmpty("",
 		toSeasonString(pr.Season, pr.Special),
-		toEpisodeString(pr.Episode1),
-		toEpisodeString(pr.Episode2),
+		toEpisodeRangeString(pr.Episode, pr.LastEpisode),
 	)
 }
 
@@ -310,3 +539,25 @@ func versionInfo(pr parser.Result) string {
 	}
 	return strings.Join(tokens, ".")
 }
+
+// mediaInfo joins what is known about the video and audio
+// streams, e.g. HDR10.HEVC.10bit.TrueHD.7.1
+func mediaInfo(pr parser.Result) string {
+	tokens := []string{}
+	if pr.HDR != parser.HDRNA {
+		tokens = append(tokens, pr.HDR.String())
+	}
+	if pr.VideoCodec != parser.VideoCodecNA {
+		tokens = append(tokens, pr.VideoCodec.String())
+	}
+	if pr.BitDepth != parser.BitDepthNA {
+		tokens = append(tokens, pr.BitDepth.String())
+	}
+	if pr.AudioCodec != parser.AudioCodecNA {
+		tokens = append(tokens, pr.AudioCodec.String())
+	}
+	if pr.AudioChannels != parser.ChannelsNA {
+		tokens = append(tokens, pr.AudioChannels.String())
+	}
+	return strings.Join(tokens, ".")
+}
@@ -16,6 +16,7 @@ type testFixture struct {
 	renamer.Parameters
 
 	tvdbResponse []tvdb.SearchResult
+	tvdbEpisodes []tvdb.Episode
 	tmdbResponse []tmdb.SearchResult
 
 	promptResponse int
@@ -63,6 +64,29 @@ var tests = []testFixture{
 			{Title: "Real Movie Title 2"},
 		},
 	},
+	{
+		Parameters: renamer.Parameters{
+			SourcePath: "../tests/fixtures/movie-edition",
+			TargetPath: "/dev/null/",
+		},
+		expectedOldFilePath: "../tests/fixtures/movie-edition/movie title/Movie.Title.1999.Directors.Cut.1080p.BluRay.x264-GRP.mkv",
+		expectedNewFilePath: "/dev/null/Real Movie Title (1999)/Real Movie Title (1999) {edition-Director's Cut} - 1080p.Blu-ray.mkv",
+		expectedNewPath:     "/dev/null/Real Movie Title (1999)/",
+		tmdbResponse:        []tmdb.SearchResult{{Title: "Real Movie Title"}},
+	},
+	{
+		Parameters: renamer.Parameters{
+			SourcePath: "../tests/fixtures/movie-id-hint",
+			TargetPath: "/dev/null/",
+		},
+		expectedOldFilePath: "../tests/fixtures/movie-id-hint/Shawshank.2001.1080p.BluRay {tmdb-278}/movie.mkv",
+		expectedNewFilePath: "/dev/null/The Shawshank Redemption (1994) {tmdb-278}/The Shawshank Redemption (1994) - 1080p.Blu-ray.mkv",
+		expectedNewPath:     "/dev/null/The Shawshank Redemption (1994) {tmdb-278}/",
+		tmdbResponse: []tmdb.SearchResult{
+			{ID: 1, Title: "Shawshank", ReleaseDate: "2001-01-01"},
+			{ID: 278, Title: "The Shawshank Redemption", ReleaseDate: "1994-09-23"},
+		},
+	},
 	{
 		Parameters: renamer.Parameters{
 			SourcePath: "../tests/fixtures/tv-parse-from-file",
@@ -110,10 +134,24 @@ var tests = []testFixture{
 			SourcePath: "../tests/fixtures/tv-dual-ep",
 		},
 		expectedOldFilePath: "../tests/fixtures/tv-dual-ep/tv show title/Title S01E03E04.mkv",
-		expectedNewFilePath: "../tests/fixtures/tv-dual-ep/Awesome Show/Season 01/Awesome Show - S01E03E04.mkv",
+		expectedNewFilePath: "../tests/fixtures/tv-dual-ep/Awesome Show/Season 01/Awesome Show - S01E03-E04.mkv",
 		expectedNewPath:     "../tests/fixtures/tv-dual-ep/Awesome Show/Season 01/",
 		tvdbResponse:        []tvdb.SearchResult{{Title: "Awesome Show"}},
 	},
+	{
+		Parameters: renamer.Parameters{
+			SourcePath: "../tests/fixtures/tv-dual-ep",
+		},
+		expectedOldFilePath: "../tests/fixtures/tv-dual-ep/tv show title/Title S01E03E04.mkv",
+		expectedNewFilePath: "../tests/fixtures/tv-dual-ep/Awesome Show/Season 01/Awesome Show - S01E03-E04 - Part One & Part Two.mkv",
+		expectedNewPath:     "../tests/fixtures/tv-dual-ep/Awesome Show/Season 01/",
+		tvdbResponse:        []tvdb.SearchResult{{ID: 42, Title: "Awesome Show"}},
+		tvdbEpisodes: []tvdb.Episode{
+			{AiredSeason: 1, AiredEpisodeNumber: 3, Title: "Part One"},
+			{AiredSeason: 1, AiredEpisodeNumber: 4, Title: "Part Two"},
+			{AiredSeason: 2, AiredEpisodeNumber: 3, Title: "Wrong Season"},
+		},
+	},
 	{
 		Parameters: renamer.Parameters{
 			SourcePath: "../tests/fixtures/tv-long-season-folder-name",
@@ -132,11 +170,46 @@ var tests = []testFixture{
 		expectedNewPath:     "../tests/fixtures/tv-special/Awesome Show/Season 00/",
 		tvdbResponse:        []tvdb.SearchResult{{Title: "Awesome Show"}},
 	},
+	{
+		Parameters: renamer.Parameters{
+			SourcePath: "../tests/fixtures/tv-anime-absolute",
+		},
+		expectedOldFilePath: "../tests/fixtures/tv-anime-absolute/One Piece/[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv",
+		expectedNewFilePath: "../tests/fixtures/tv-anime-absolute/One Piece/Season 21/One Piece - S21E179 - Gear Five - 1080p.mkv",
+		expectedNewPath:     "../tests/fixtures/tv-anime-absolute/One Piece/Season 21/",
+		tvdbResponse:        []tvdb.SearchResult{{ID: 81797, Title: "One Piece"}},
+		tvdbEpisodes: []tvdb.Episode{
+			{AiredSeason: 0, AiredEpisodeNumber: 30, AbsoluteNumber: 1071, Title: "Special"},
+			{AiredSeason: 21, AiredEpisodeNumber: 179, AbsoluteNumber: 1071, Title: "Gear Five"},
+		},
+	},
+	{
+		Parameters: renamer.Parameters{
+			SourcePath: "../tests/fixtures/tv-daily-show",
+		},
+		expectedOldFilePath: "../tests/fixtures/tv-daily-show/daily show/The.Daily.Show.2021.03.15.Guest.720p.WEB.h264.mkv",
+		expectedNewFilePath: "../tests/fixtures/tv-daily-show/The Daily Show/Season 26/The Daily Show - S26E66 - Guest - 720p.WEB-Rip.mkv",
+		expectedNewPath:     "../tests/fixtures/tv-daily-show/The Daily Show/Season 26/",
+		tvdbResponse:        []tvdb.SearchResult{{ID: 71256, Title: "The Daily Show"}},
+		tvdbEpisodes: []tvdb.Episode{
+			{AiredSeason: 26, AiredEpisodeNumber: 65, FirstAired: "2021-03-11", Title: "Other Guest"},
+			{AiredSeason: 26, AiredEpisodeNumber: 66, FirstAired: "2021-03-15", Title: "Guest"},
+		},
+	},
+	{
+		Parameters: renamer.Parameters{
+			SourcePath: "../tests/fixtures/tv-daily-show",
+		},
+		expectedOldFilePath: "../tests/fixtures/tv-daily-show/daily show/The.Daily.Show.2021.03.15.Guest.720p.WEB.h264.mkv",
+		expectedNewFilePath: "../tests/fixtures/tv-daily-show/The Daily Show/Season 2021/The Daily Show - 2021-03-15 - 720p.WEB-Rip.mkv",
+		expectedNewPath:     "../tests/fixtures/tv-daily-show/The Daily Show/Season 2021/",
+		tvdbResponse:        []tvdb.SearchResult{{ID: 71256, Title: "The Daily Show"}},
+	},
 }
 
 func TestFixtures(t *testing.T) {
 	for _, tc := range tests {
-		mockedTVDB := mockTVDBResponse(tc.tvdbResponse)
+		mockedTVDB := mock.NewMockTVDBWithEpisodes(tvdb.SearchResponse{Results: tc.tvdbResponse}, tc.tvdbEpisodes, nil)
 		mockedTMDB := mockTMDBResponse(tc.tmdbResponse)
 		mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
 			if oldPath != tc.expectedOldFilePath {
@@ -175,3 +248,36 @@ func mockTVDBResponse(results []tvdb.SearchResult) tvdb.Client {
 func mockTMDBResponse(results []tmdb.SearchResult) tmdb.Client {
 	return mock.NewMockTMDB(tmdb.SearchResponse{Results: results}, nil)
 }
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		preferProbe bool
+		expected    string
+	}{
+		{false, "/dev/null/Real Movie Title (1999)/Real Movie Title (1999) - German.720p.DL.mkv"},
+		{true, "/dev/null/Real Movie Title (1999)/Real Movie Title (1999) - German.2160p.DL.mkv"},
+	}
+	for _, tc := range tests {
+		mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
+			if newPath != tc.expected {
+				t.Errorf("\nExpected: %s\nReceived: %s", tc.expected, newPath)
+			}
+			return nil
+		}, func(path string) error { return nil })
+
+		params := renamer.NewParameters("../tests/fixtures/movie-probe", "/dev/null", parser.Result{}, []string{}, false, false, false)
+		params.Probe = true
+		params.PreferProbe = tc.preferProbe
+		n := renamer.New(
+			params,
+			search.NewSearcher(
+				mockTMDBResponse([]tmdb.SearchResult{{Title: "Real Movie Title"}}),
+				mockTVDBResponse(nil),
+				mock.NewMockPrompter(nil, nil, nil),
+			),
+			mockedFS)
+		if err := n.Run(); err != nil {
+			t.Error(err)
+		}
+	}
+}
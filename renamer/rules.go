@@ -0,0 +1,136 @@
+package renamer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/florianehmke/plexname/config"
+	"github.com/florianehmke/plexname/parser"
+)
+
+// Rule fixes the parse result of the files whose source name matches it,
+// e.g. of a show that always misparses. A regex is matched against the
+// whole source path, a glob against the name of the file and each of its
+// folders. The other fields are overrides as given by the flags of the same
+// name, unset fields are left alone.
+type Rule struct {
+	Regex string `json:"regex,omitempty"`
+	Glob  string `json:"glob,omitempty"`
+
+	Title      string `json:"title,omitempty"`
+	MediaType  string `json:"media_type,omitempty"`
+	Year       int    `json:"year,omitempty"`
+	Season     int    `json:"season,omitempty"`
+	Resolution string `json:"resolution,omitempty"`
+	Source     string `json:"source,omitempty"`
+	Language   string `json:"language,omitempty"`
+	Edition    string `json:"edition,omitempty"`
+
+	// TMDBID, TVDBID and IMDbID pin matching movies and series, see Pin.
+	TMDBID int    `json:"tmdb_id,omitempty"`
+	TVDBID int    `json:"tvdb_id,omitempty"`
+	IMDbID string `json:"imdb_id,omitempty"`
+
+	rxp       *regexp.Regexp
+	overrides parser.Result
+}
+
+// Rules are applied in order, of several matching rules the later ones win.
+type Rules []Rule
+
+// RulesPath returns the path of the default rules file.
+func RulesPath() string {
+	return filepath.Join(config.Dir(), "rules.json")
+}
+
+// LoadRules reads and checks the rules file at path.
+// A missing file yields no rules.
+func LoadRules(path string) (Rules, error) {
+	var rules Rules
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return rules, nil
+	}
+	if err != nil {
+		return rules, fmt.Errorf("could not read rules file %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("could not parse rules file %s: %v", path, err)
+	}
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return rules, fmt.Errorf("invalid rule %d in %s: %v", i+1, path, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rule) compile() error {
+	if (r.Regex == "") == (r.Glob == "") {
+		return errors.New("either regex or glob has to be set")
+	}
+	if r.Regex != "" {
+		rxp, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return err
+		}
+		r.rxp = rxp
+	}
+	if _, err := path.Match(r.Glob, ""); err != nil {
+		return err
+	}
+
+	var err error
+	o := parser.Result{Title: r.Title, Year: r.Year, Season: r.Season, Edition: r.Edition}
+	if o.MediaType, err = parser.ParseMediaType(r.MediaType); err != nil {
+		return err
+	}
+	if o.Resolution, err = parser.ParseResolution(r.Resolution); err != nil {
+		return err
+	}
+	if o.Source, err = parser.ParseSource(r.Source); err != nil {
+		return err
+	}
+	if o.Language, err = parser.ParseLanguage(r.Language); err != nil {
+		return err
+	}
+	r.overrides = o
+	return nil
+}
+
+func (r *Rule) matches(file string) bool {
+	if r.rxp != nil {
+		return r.rxp.MatchString(file)
+	}
+	for _, name := range strings.Split(file, "/") {
+		if ok, _ := path.Match(r.Glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// overridesFor returns the overrides for file, the flags win over the rules.
+func (r *Renamer) overridesFor(file string) parser.Result {
+	overrides, _ := r.params.Rules.match(file)
+	return overrides.Merge(r.params.Overrides)
+}
+
+// match returns the merged overrides and pinned ids of all rules that match file.
+func (rs Rules) match(file string) (overrides parser.Result, pin Pin) {
+	for _, r := range rs {
+		if !r.matches(file) {
+			continue
+		}
+		overrides = overrides.Merge(r.overrides)
+		pin = pin.merge(Pin{TMDBID: r.TMDBID, TVDBID: r.TVDBID, IMDbID: r.IMDbID})
+	}
+	return overrides, pin
+}
@@ -0,0 +1,115 @@
+package renamer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/renamer"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tmdb"
+	"github.com/florianehmke/plexname/tvdb"
+)
+
+func writeRules(t *testing.T, rules string) string {
+	dir, err := ioutil.TempDir("", "plexname")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "rules.json")
+	if err := ioutil.WriteFile(path, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	rules, err := renamer.LoadRules(filepath.Join(os.TempDir(), "does-not-exist", "rules.json"))
+	if err != nil || len(rules) != 0 {
+		t.Errorf("expected no rules for a missing file, got %v, %v", rules, err)
+	}
+
+	invalid := []string{
+		`[{"regex": "(unclosed"}]`,
+		`[{"glob": "*", "regex": ".*"}]`,
+		`[{"title": "no pattern"}]`,
+		`[{"glob": "*", "resolution": "1081p"}]`,
+		`{"glob": "*"}`,
+	}
+	for _, rules := range invalid {
+		path := writeRules(t, rules)
+		if _, err := renamer.LoadRules(path); err == nil {
+			t.Errorf("expected an error for %s", rules)
+		}
+		os.RemoveAll(filepath.Dir(path))
+	}
+}
+
+func TestRules(t *testing.T) {
+	path := writeRules(t, `[
+		{"glob": "TV-Show.*", "title": "Doctor Who", "year": 1963},
+		{"regex": "tv show title/TV-Show\\.S02", "year": 2005, "tvdb_id": 78804}
+	]`)
+	defer os.RemoveAll(filepath.Dir(path))
+	rules, err := renamer.LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/dev/null/Doctor Who (2005) {tvdb-78804}/Season 02/Doctor Who (2005) - S02E13 - German.1080p.DL.Blu-ray.Remux.mkv"
+	mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
+		if newPath != expected {
+			t.Errorf("\nExpected: %s\nReceived: %s", expected, newPath)
+		}
+		return nil
+	}, func(path string) error { return nil })
+	mockedTVDB := mock.NewMockTVDB(tvdb.SearchResponse{Results: []tvdb.SearchResult{
+		{ID: 76107, Title: "Doctor Who", FirstAired: "1963-11-23"},
+		{ID: 78804, Title: "Doctor Who", FirstAired: "2005-03-26"},
+	}}, nil)
+	mockedPrompter := mock.NewMockPrompter(func(question string) (int, error) {
+		t.Errorf("expected no prompt, got %s", question)
+		return 0, nil
+	}, nil, nil)
+
+	params := renamer.NewParameters("../tests/fixtures/tv-parse-from-file", "/dev/null", parser.Result{}, []string{}, false, false, false)
+	params.Rules = rules
+	n := renamer.New(params, search.NewSearcher(mockTMDBResponse(nil), mockedTVDB, mockedPrompter), mockedFS)
+	if err := n.Run(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRules_PreferProbe(t *testing.T) {
+	path := writeRules(t, `[{"regex": "Movie\\.Title", "resolution": "1080p"}]`)
+	defer os.RemoveAll(filepath.Dir(path))
+	rules, err := renamer.LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The rule wins over the probed 2160p.
+	expected := "/dev/null/Real Movie Title (1999)/Real Movie Title (1999) - German.1080p.DL.mkv"
+	mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
+		if newPath != expected {
+			t.Errorf("\nExpected: %s\nReceived: %s", expected, newPath)
+		}
+		return nil
+	}, func(path string) error { return nil })
+
+	params := renamer.NewParameters("../tests/fixtures/movie-probe", "/dev/null", parser.Result{}, []string{}, false, false, false)
+	params.Rules = rules
+	params.Probe = true
+	params.PreferProbe = true
+	n := renamer.New(params, search.NewSearcher(
+		mockTMDBResponse([]tmdb.SearchResult{{Title: "Real Movie Title"}}),
+		mockTVDBResponse(nil),
+		mock.NewMockPrompter(nil, nil, nil),
+	), mockedFS)
+	if err := n.Run(); err != nil {
+		t.Error(err)
+	}
+}
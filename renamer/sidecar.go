@@ -0,0 +1,197 @@
+package renamer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/florianehmke/plexname/parser"
+)
+
+var (
+	videoExtensions = map[string]bool{
+		"mkv": true, "mp4": true, "m4v": true, "avi": true, "mov": true,
+		"wmv": true, "ts": true, "m2ts": true, "mpg": true, "mpeg": true,
+	}
+	subtitleExtensions = map[string]bool{
+		"srt": true, "ass": true, "ssa": true, "sub": true, "idx": true,
+		"vtt": true, "smi": true, "sup": true,
+	}
+	imageExtensions = map[string]bool{
+		"jpg": true, "jpeg": true, "png": true, "tbn": true,
+	}
+	subtitleFolders = map[string]bool{
+		"subs": true, "sub": true, "subtitles": true,
+	}
+	posterNames = map[string]bool{
+		"poster": true, "folder": true, "cover": true,
+	}
+	fanartNames = map[string]bool{
+		"fanart": true, "backdrop": true, "background": true,
+	}
+)
+
+// sidecar is a file that belongs to a video, e.g. a subtitle.
+type sidecar struct {
+	currentFilePath string
+
+	// suffix replaces the extension of the video's new path, e.g. .en.forced.srt
+	suffix string
+}
+
+// newFilePath of the sidecar, given the new path of its video.
+func (s sidecar) newFilePath(videoPath string) string {
+	return strings.TrimSuffix(videoPath, path.Ext(videoPath)) + s.suffix
+}
+
+// groupSidecars attaches subtitles, nfo files and posters to their video.
+//
+// A sidecar belongs to the video whose base name it starts with, otherwise
+// to the only video in its folder (or in the parent of a Subs folder).
+// Sidecars without a video are kept as files of their own.
+func groupSidecars(files []fileInfo) []fileInfo {
+	var result []fileInfo
+	videosByDir := map[string][]int{}
+	for _, f := range files {
+		if isVideo(f.currentFilePath) {
+			videosByDir[path.Dir(f.currentFilePath)] = append(videosByDir[path.Dir(f.currentFilePath)], len(result))
+			result = append(result, f)
+		}
+	}
+
+	for _, f := range files {
+		if isVideo(f.currentFilePath) {
+			continue
+		}
+		if !isSidecar(f.currentFilePath) {
+			result = append(result, f)
+			continue
+		}
+
+		dir, name := path.Split(f.currentFilePath)
+		dir = path.Clean(dir)
+
+		video, rest := -1, ""
+		for _, i := range videosByDir[dir] {
+			base := baseName(result[i].currentFilePath)
+			if strings.HasPrefix(strings.ToLower(name), strings.ToLower(base)+".") {
+				if video == -1 || len(base) > len(baseName(result[video].currentFilePath)) {
+					video, rest = i, name[len(base):]
+				}
+			}
+		}
+		if video == -1 && (!imageExtensions[extension(name)] || isArtwork(name)) {
+			candidates := videosByDir[dir]
+			if len(candidates) == 0 && subtitleFolders[strings.ToLower(path.Base(dir))] {
+				candidates = videosByDir[path.Dir(dir)]
+			}
+			if len(candidates) == 1 {
+				video, rest = candidates[0], name
+			}
+		}
+		if video == -1 {
+			result = append(result, f)
+			continue
+		}
+
+		result[video].sidecars = append(result[video].sidecars, sidecar{
+			currentFilePath: f.currentFilePath,
+			suffix:          sidecarSuffix(rest),
+		})
+	}
+	for i := range result {
+		numberDuplicates(result[i].sidecars)
+	}
+	return result
+}
+
+// numberDuplicates keeps sidecars of the same video apart that end up
+// with the same suffix, e.g. two English subtitles become .en.srt and
+// .en.2.srt. Otherwise they would conflict with each other.
+func numberDuplicates(sidecars []sidecar) {
+	seen := map[string]int{}
+	for i, s := range sidecars {
+		seen[s.suffix]++
+		if n := seen[s.suffix]; n > 1 {
+			ext := path.Ext(s.suffix)
+			sidecars[i].suffix = fmt.Sprintf("%s.%d%s", strings.TrimSuffix(s.suffix, ext), n, ext)
+		}
+	}
+}
+
+// sidecarSuffix builds the plex style suffix from what is left of a sidecar's
+// name after removing the video's base name, e.g. ".English.Forced.srt" yields
+// ".en.forced.srt" and "poster.jpg" yields ".jpg".
+func sidecarSuffix(rest string) string {
+	ext := strings.ToLower(path.Ext(rest))
+	tokens := strings.FieldsFunc(strings.TrimSuffix(rest, path.Ext(rest)), func(r rune) bool {
+		return r == '.' || r == '_' || r == '-' || r == ' ' || r == '[' || r == ']' || r == '(' || r == ')'
+	})
+
+	var lang parser.Language
+	var forced, sdh, fanart bool
+	for _, t := range tokens {
+		lt := strings.ToLower(t)
+		switch {
+		case lt == "forced" || lt == "foreign":
+			forced = true
+		case lt == "sdh" || lt == "hi" || lt == "cc":
+			sdh = true
+		case fanartNames[lt]:
+			fanart = true
+		default:
+			if l, ok := parser.ParseLanguageCode(lt); ok && lang == parser.LangNA {
+				lang = l
+			}
+		}
+	}
+
+	if imageExtensions[strings.TrimPrefix(ext, ".")] {
+		if fanart {
+			return "-fanart" + ext
+		}
+		return ext
+	}
+	if !subtitleExtensions[strings.TrimPrefix(ext, ".")] {
+		return ext
+	}
+	suffix := ""
+	if lang != parser.LangNA {
+		suffix += "." + lang.Code()
+	}
+	if forced {
+		suffix += ".forced"
+	}
+	if sdh {
+		suffix += ".sdh"
+	}
+	return suffix + ext
+}
+
+func isVideo(p string) bool {
+	return videoExtensions[extension(p)]
+}
+
+func isSidecar(p string) bool {
+	ext := extension(p)
+	if subtitleExtensions[ext] || ext == "nfo" {
+		return true
+	}
+	return imageExtensions[ext]
+}
+
+// isArtwork tells whether p is named like a poster or fanart,
+// other images only belong to a video if they share its name.
+func isArtwork(p string) bool {
+	name := strings.ToLower(baseName(p))
+	return posterNames[name] || fanartNames[name]
+}
+
+func extension(p string) string {
+	return strings.ToLower(strings.TrimPrefix(path.Ext(p), "."))
+}
+
+func baseName(p string) string {
+	name := path.Base(p)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
@@ -0,0 +1,126 @@
+package renamer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/florianehmke/plexname/fs"
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/renamer"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tmdb"
+)
+
+func TestSidecars(t *testing.T) {
+	tmp := tempDir(t)
+	defer os.RemoveAll(tmp)
+	download := filepath.Join(tmp, "downloads", "Movie.1999.German.1080p.BluRay-group")
+	for _, f := range []string{
+		"Movie.1999.German.1080p.BluRay-group.mkv",
+		"Movie.1999.German.1080p.BluRay-group.nfo",
+		"Movie.1999.German.1080p.BluRay-group.English.Forced.srt",
+		"Movie.1999.German.1080p.BluRay-group.de.sdh.srt",
+		"Movie.1999.German.1080p.BluRay-group.fanart.jpg",
+		"poster.jpg",
+		"Subs/2_English.srt",
+		"Subs/3_English.srt",
+	} {
+		mustWriteFile(t, filepath.Join(download, f), f)
+	}
+
+	params := renamer.NewParameters(filepath.Join(tmp, "downloads"), filepath.Join(tmp, "movies"), parser.Result{}, nil, false, false, false)
+	n := renamer.New(params, search.NewSearcher(
+		mockTMDBResponse([]tmdb.SearchResult{{Title: "Movie"}}),
+		mockTVDBResponse(nil),
+		mock.NewMockPrompter(nil, nil, nil),
+	), fs.NewFileSystem(false))
+	if err := n.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"Movie (1999) - German.1080p.Blu-ray-fanart.jpg",
+		"Movie (1999) - German.1080p.Blu-ray.de.sdh.srt",
+		"Movie (1999) - German.1080p.Blu-ray.en.2.srt",
+		"Movie (1999) - German.1080p.Blu-ray.en.forced.srt",
+		"Movie (1999) - German.1080p.Blu-ray.en.srt",
+		"Movie (1999) - German.1080p.Blu-ray.jpg",
+		"Movie (1999) - German.1080p.Blu-ray.mkv",
+		"Movie (1999) - German.1080p.Blu-ray.nfo",
+	}
+	if files := listFiles(t, filepath.Join(tmp, "movies", "Movie (1999)")); !equal(files, expected) {
+		t.Errorf("\nExpected: %v\nReceived: %v", expected, files)
+	}
+}
+
+func TestSidecarConflicts(t *testing.T) {
+	tests := []struct {
+		policy        renamer.ConflictPolicy
+		extensions    []string
+		expectError   bool
+		expectedFiles []string
+	}{
+		{
+			policy:      renamer.ConflictFail,
+			expectError: true,
+			expectedFiles: []string{
+				"Movie (1999) - 1080p.Blu-ray.en.srt",
+			},
+		},
+		{
+			policy: renamer.ConflictSkip,
+			expectedFiles: []string{
+				"Movie (1999) - 1080p.Blu-ray.en.srt",
+				"Movie (1999) - 1080p.Blu-ray.mkv",
+			},
+		},
+		{
+			policy: renamer.ConflictSuffix,
+			expectedFiles: []string{
+				"Movie (1999) - 1080p.Blu-ray.en (2).srt",
+				"Movie (1999) - 1080p.Blu-ray.en.srt",
+				"Movie (1999) - 1080p.Blu-ray.mkv",
+			},
+		},
+		{
+			policy:     renamer.ConflictFail,
+			extensions: []string{"mkv"},
+			expectedFiles: []string{
+				"Movie (1999) - 1080p.Blu-ray.en.srt",
+				"Movie (1999) - 1080p.Blu-ray.mkv",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tmp := tempDir(t)
+		defer os.RemoveAll(tmp)
+		download := filepath.Join(tmp, "downloads", "Movie.1999.1080p.BluRay-group")
+		for _, f := range []string{
+			"Movie.1999.1080p.BluRay-group.mkv",
+			"Subs/English.srt",
+		} {
+			mustWriteFile(t, filepath.Join(download, f), f)
+		}
+		// The subtitle of an earlier download is in the way.
+		mustWriteFile(t, filepath.Join(tmp, "movies", "Movie (1999)", "Movie (1999) - 1080p.Blu-ray.en.srt"), "existing")
+
+		params := renamer.NewParameters(filepath.Join(tmp, "downloads"), filepath.Join(tmp, "movies"), parser.Result{}, tc.extensions, false, false, false)
+		params.ConflictPolicy = tc.policy
+		n := renamer.New(params, search.NewSearcher(
+			mockTMDBResponse([]tmdb.SearchResult{{Title: "Movie"}}),
+			mockTVDBResponse(nil),
+			mock.NewMockPrompter(nil, nil, nil),
+		), fs.NewFileSystem(false))
+
+		err := n.Run()
+		if tc.expectError != (err != nil) {
+			t.Errorf("policy %s: unexpected error %v", tc.policy, err)
+		}
+		if files := listFiles(t, filepath.Join(tmp, "movies", "Movie (1999)")); !equal(files, tc.expectedFiles) {
+			t.Errorf("policy %s, extensions %v:\nExpected: %v\nReceived: %v", tc.policy, tc.extensions, tc.expectedFiles, files)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package renamer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/probe"
+	"github.com/florianehmke/plexname/search"
+)
+
+// Template kinds as used by the -template flag and the config file.
+const (
+	MovieTemplate   = "movie"
+	EpisodeTemplate = "episode"
+	SeasonTemplate  = "season"
+)
+
+// Default templates, they produce the classic plex layout:
+//
+//	Title (Year)/Title (Year) - German.1080p.DL.Blu-ray.Remux.mkv
+//	Title (Year)/Title (Year) {edition-Director's Cut} - German.1080p.DL.Blu-ray.Remux.mkv
+//	Title (Year)/Season 01/Title (Year) - S01E02 - Episode Title - German.1080p.DL.Blu-ray.Remux.mkv
+//
+// Media that is pinned to an id gets it in its folder, e.g. Title (Year) {tmdb-603}/.
+const (
+	DefaultMovieTemplate   = `{{join " " .Name .ID}}/{{join " - " (join " " .Name .Edition) .Version}}`
+	DefaultEpisodeTemplate = `{{join " - " .Name .TV .EpisodeTitle .Version}}`
+	DefaultSeasonTemplate  = `{{join " " .Name .ID}}/Season {{pad .Parsed.Season 2}}`
+)
+
+// Templates holds the naming templates used to build new paths.
+//
+// The movie template yields the path of a movie file relative to the target,
+// the season template the path of a season folder relative to the target and
+// the episode template the file name of an episode within its season folder.
+// All results are without extension and use slashes as separator.
+type Templates struct {
+	Movie   *template.Template
+	Episode *template.Template
+	Season  *template.Template
+}
+
+// templateData is the data available to a template.
+type templateData struct {
+	Name    string // e.g. Title (Year)
+	Title   string // title as found online
+	Year    int    // year from the parser or the search
+	TV      string // e.g. S01E02
+	Version string // e.g. German.1080p.DL.Blu-ray.Remux
+	Edition string // e.g. {edition-Director's Cut}
+	ID      string // e.g. {tmdb-603}, only set for media pinned to an id
+
+	// Media is what is known about the streams, e.g. HDR10.HEVC.10bit.TrueHD.7.1,
+	// the other fields hold its single parts. Unknown parts are empty.
+	Media         string
+	HDR           string
+	VideoCodec    string
+	BitDepth      string
+	AudioCodec    string
+	AudioChannels string
+
+	EpisodeTitle string // e.g. Pilot, multiple episodes are joined by " & "
+
+	Parsed parser.Result
+	Search search.Result
+	Probe  probe.Info // only set with -probe, e.g. {{.Probe.Duration}}
+}
+
+var templateFuncs = template.FuncMap{
+	"join": joinNonEmpty,
+	"pad": func(n int, width int) string {
+		return fmt.Sprintf("%0*d", width, n)
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// DefaultTemplates returns the default templates.
+func DefaultTemplates() Templates {
+	t, err := ParseTemplates(nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ParseTemplates parses and checks the given templates (keyed by kind).
+// Kinds that are missing are set to their default.
+func ParseTemplates(texts map[string]string) (Templates, error) {
+	defaults := map[string]string{
+		MovieTemplate:   DefaultMovieTemplate,
+		EpisodeTemplate: DefaultEpisodeTemplate,
+		SeasonTemplate:  DefaultSeasonTemplate,
+	}
+	for kind := range texts {
+		if _, ok := defaults[kind]; !ok {
+			return Templates{}, fmt.Errorf("unknown template kind: %s", kind)
+		}
+	}
+
+	parsed := map[string]*template.Template{}
+	for kind, def := range defaults {
+		text := def
+		if t, ok := texts[kind]; ok && t != "" {
+			text = t
+		}
+		t, err := template.New(kind).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return Templates{}, fmt.Errorf("invalid %s template: %v", kind, err)
+		}
+		if err := checkTemplate(t); err != nil {
+			return Templates{}, fmt.Errorf("invalid %s template: %v", kind, err)
+		}
+		parsed[kind] = t
+	}
+
+	return Templates{
+		Movie:   parsed[MovieTemplate],
+		Episode: parsed[EpisodeTemplate],
+		Season:  parsed[SeasonTemplate],
+	}, nil
+}
+
+// checkTemplate executes t with sample data to catch unknown fields
+// and results that are not usable as a relative path.
+func checkTemplate(t *template.Template) error {
+	samples := []struct {
+		pr parser.Result
+		sr search.Result
+	}{
+		{
+			pr: parser.Result{Title: "title", MediaType: parser.MediaTypeMovie, Year: 1999},
+			sr: search.Result{Title: "Title", Year: 1999},
+		},
+		{
+			pr: parser.Result{Title: "title", MediaType: parser.MediaTypeTV, Season: 1, Episode: 2},
+			sr: search.Result{Title: "Title"},
+		},
+	}
+	for _, s := range samples {
+		data, err := newTemplateData(s.pr, s.sr)
+		if err != nil {
+			return err
+		}
+		if _, err := execute(t, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute runs t and makes sure the result is a usable relative path.
+func execute(t *template.Template, data templateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	p := strings.TrimSpace(buf.String())
+	if strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("result %q must be a relative path", p)
+	}
+	p = path.Clean(p)
+	if p == "." || p == "" {
+		return "", errors.New("result must not be empty")
+	}
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("result %q must not leave the target directory", p)
+	}
+	return p, nil
+}
+
+func newTemplateData(pr parser.Result, sr search.Result) (templateData, error) {
+	name, err := plexName(pr, sr)
+	if err != nil {
+		return templateData{}, err
+	}
+	data := templateData{
+		Name:    name,
+		Title:   sr.Title,
+		Year:    mediaYear(pr, sr),
+		ID:      idTag(pr, sr),
+		Version: versionInfo(pr),
+		Media:   mediaInfo(pr),
+		Parsed:  pr,
+		Search:  sr,
+	}
+	if pr.Edition != "" {
+		data.Edition = "{edition-" + pr.Edition + "}"
+	}
+	if pr.HDR != parser.HDRNA {
+		data.HDR = pr.HDR.String()
+	}
+	if pr.VideoCodec != parser.VideoCodecNA {
+		data.VideoCodec = pr.VideoCodec.String()
+	}
+	if pr.BitDepth != parser.BitDepthNA {
+		data.BitDepth = pr.BitDepth.String()
+	}
+	if pr.AudioCodec != parser.AudioCodecNA {
+		data.AudioCodec = pr.AudioCodec.String()
+	}
+	if pr.AudioChannels != parser.ChannelsNA {
+		data.AudioChannels = pr.AudioChannels.String()
+	}
+	if pr.IsTV() {
+		data.TV = tvInfo(pr)
+	}
+	return data, nil
+}
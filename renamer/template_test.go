@@ -0,0 +1,97 @@
+package renamer_test
+
+import (
+	"testing"
+
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/parser"
+	"github.com/florianehmke/plexname/renamer"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tvdb"
+)
+
+func TestParseTemplates_Invalid(t *testing.T) {
+	invalid := []map[string]string{
+		{"movie": "{{.Name"},
+		{"movie": "{{.Unknown}}"},
+		{"season": "{{.Parsed.Unknown}}"},
+		{"episode": "/{{.Name}}"},
+		{"episode": "../{{.Name}}"},
+		{"episode": "{{if false}}x{{end}}"},
+		{"poster": "{{.Name}}"},
+	}
+	for _, texts := range invalid {
+		if _, err := renamer.ParseTemplates(texts); err == nil {
+			t.Errorf("expected an error for %v", texts)
+		}
+	}
+}
+
+func TestCustomTemplates(t *testing.T) {
+	templates, err := renamer.ParseTemplates(map[string]string{
+		"season":  `{{.Title}}/Season {{.Parsed.Season}}`,
+		"episode": `{{.Title}} {{.TV}} {{.Parsed.Resolution}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedNewPath := "/dev/null/Real TV Show Title/Season 2/"
+	expectedNewFilePath := "/dev/null/Real TV Show Title/Season 2/Real TV Show Title S02E13 1080p.mkv"
+	mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
+		if newPath != expectedNewFilePath {
+			t.Errorf("\nExpected: %s\nReceived: %s", expectedNewFilePath, newPath)
+		}
+		return nil
+	}, func(path string) error {
+		if path != expectedNewPath {
+			t.Errorf("\nExpected: %s\nReceived: %s", expectedNewPath, path)
+		}
+		return nil
+	})
+
+	params := renamer.NewParameters("../tests/fixtures/tv-parse-from-file", "/dev/null", parser.Result{}, []string{}, false, false, false)
+	params.Templates = templates
+	n := renamer.New(
+		params,
+		search.NewSearcher(
+			mockTMDBResponse(nil),
+			mockTVDBResponse([]tvdb.SearchResult{{Title: "Real TV Show Title", FirstAired: "1981-01-01"}}),
+			mock.NewMockPrompter(nil, nil, nil),
+		),
+		mockedFS)
+	if err := n.Run(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCustomTemplates_Media(t *testing.T) {
+	templates, err := renamer.ParseTemplates(map[string]string{
+		"episode": `{{join " - " .Name .TV .Version}}.{{.Media}} {{.VideoCodec}} {{.AudioCodec}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedNewFilePath := "/dev/null/Real TV Show Title/Season 02/Real TV Show Title - S02E13 - German.1080p.DL.Blu-ray.Remux.AVC.DTS AVC DTS.mkv"
+	mockedFS := mock.NewMockFS(func(oldPath string, newPath string) error {
+		if newPath != expectedNewFilePath {
+			t.Errorf("\nExpected: %s\nReceived: %s", expectedNewFilePath, newPath)
+		}
+		return nil
+	}, func(path string) error { return nil })
+
+	params := renamer.NewParameters("../tests/fixtures/tv-parse-from-file", "/dev/null", parser.Result{}, []string{}, false, false, false)
+	params.Templates = templates
+	n := renamer.New(
+		params,
+		search.NewSearcher(
+			mockTMDBResponse(nil),
+			mockTVDBResponse([]tvdb.SearchResult{{Title: "Real TV Show Title"}}),
+			mock.NewMockPrompter(nil, nil, nil),
+		),
+		mockedFS)
+	if err := n.Run(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,89 @@
+package search
+
+import "github.com/florianehmke/plexname/parser"
+
+// Weights of the single criteria of a score, they add up to 1.
+const (
+	titleWeight      = 0.6
+	yearWeight       = 0.25
+	popularityWeight = 0.15
+)
+
+// Score rates how well r matches query, from 0 (no match) to 1 (perfect match).
+//
+// It combines the similarity of the titles, the distance of the years and
+// the popularity of r relative to maxPopularity, the highest popularity
+// among all candidates. Criteria that are unknown, a missing year on either
+// side or no popularity at all (TVDB has none), are left out and the weights
+// of the others are scaled up, so an exact title alone scores 1.
+func Score(query Query, r Result, maxPopularity float64) float64 {
+	score := titleWeight * titleSimilarity(query.Title, r.Title)
+	weights := titleWeight
+	if query.Year != 0 && r.Year != 0 {
+		score += yearWeight * yearCloseness(query.Year, r.Year)
+		weights += yearWeight
+	}
+	if maxPopularity > 0 {
+		score += popularityWeight * r.Popularity / maxPopularity
+		weights += popularityWeight
+	}
+	return score / weights
+}
+
+// titleSimilarity is 1 minus the normalized edit distance of both titles,
+// compared by their parser.TitleKey.
+func titleSimilarity(a, b string) float64 {
+	ra, rb := []rune(parser.TitleKey(a)), []rune(parser.TitleKey(b))
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// yearCloseness is 1 for the same year and falls off with distance.
+func yearCloseness(a, b int) float64 {
+	switch d := a - b; {
+	case d == 0:
+		return 1
+	case d == 1 || d == -1:
+		return 0.75
+	case d == 2 || d == -2:
+		return 0.25
+	default:
+		return 0
+	}
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
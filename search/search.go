@@ -3,25 +3,83 @@ package search
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/florianehmke/plexname/parser"
 	"github.com/florianehmke/plexname/prompt"
 	"github.com/florianehmke/plexname/tmdb"
 	"github.com/florianehmke/plexname/tvdb"
 )
 
 type Result struct {
-	Title string
-	Year  int
+	ID         int // TMDB id for movies, id of the provider for series
+	Title      string
+	Year       int
+	Popularity float64
+
+	// Provider of series, see UseTVProvider. Empty means TVDB.
+	Provider string `json:",omitempty"`
+
+	// Pinned results were looked up by their id rather than found by title.
+	Pinned bool `json:",omitempty"`
 }
 
 type Query struct {
 	Title string
 	Year  int
+
+	// ID pins the result, a TMDB id for movies and an id of Provider
+	// for series. IMDbID does the same if no ID is known, e.g. tt0111161.
+	ID     int    `json:",omitempty"`
+	IMDbID string `json:",omitempty"`
+
+	// Provider of the ID of a series, see UseTVProvider. Empty means TVDB.
+	Provider string `json:",omitempty"`
+}
+
+// Pinned tells if the result of q is given by an id.
+func (q Query) Pinned() bool {
+	return q.ID != 0 || q.IMDbID != ""
 }
 
 type Searcher interface {
 	SearchMovie(query Query) (Result, error)
 	SearchTV(query Query) (Result, error)
+	EpisodeTitle(series Result, season, episode int) (string, error)
+	AbsoluteEpisode(series Result, absolute int) (season, episode int, err error)
+	AiredEpisode(series Result, date time.Time) (season, episode int, err error)
+}
+
+// Kinds of searches, as used by a ChoiceStore.
+const (
+	KindMovie = "movie"
+	KindTV    = "tv"
+)
+
+// ChoiceStore remembers which result the user picked for a query.
+type ChoiceStore interface {
+	Choice(kind string, query Query) (Result, bool)
+	Remember(kind string, query Query, result Result) error
+}
+
+// RememberChoices makes s consult store before asking the user to pick one of
+// multiple results, and remember every pick in it.
+func RememberChoices(s Searcher, store ChoiceStore) Searcher {
+	if impl, ok := s.(*searcher); ok {
+		impl.choices = store
+	}
+	return s
+}
+
+// UnresolvedError is returned by a non-interactive searcher
+// if no candidate is good enough to be picked automatically.
+type UnresolvedError struct {
+	Query  Query
+	Reason string
+}
+
+func (e *UnresolvedError) Error() string {
+	return fmt.Sprintf("unresolved '%s': %s", e.Query.Title, e.Reason)
 }
 
 type searcher struct {
@@ -29,7 +87,31 @@ type searcher struct {
 	tvdbClient tvdb.Client
 	prompter   prompt.Prompter
 
-	cache map[Query]Result
+	// nonInteractive searchers never prompt, they pick the
+	// best candidate if its score reaches the threshold.
+	nonInteractive bool
+	threshold      float64
+
+	// choices made in earlier runs, might be nil.
+	choices ChoiceStore
+
+	// tvProvider is searched for series first, see UseTVProvider.
+	tvProvider string
+
+	cache    map[Query]Result
+	episodes map[seriesKey][]tvdb.Episode
+
+	// titles of movies besides the one in the metadata language. The
+	// original titles come with the search results, the localized ones
+	// are looked up when they are needed. Series have none.
+	titles    map[titleKey][]string
+	localized map[titleKey]bool
+}
+
+// titleKey identifies a movie or series, ids of different kinds clash.
+type titleKey struct {
+	kind string
+	id   int
 }
 
 func NewSearcher(tmdbClient tmdb.Client, tvdbClient tvdb.Client, prompter prompt.Prompter) Searcher {
@@ -38,6 +120,25 @@ func NewSearcher(tmdbClient tmdb.Client, tvdbClient tvdb.Client, prompter prompt
 		tvdbClient: tvdbClient,
 		prompter:   prompter,
 		cache:      map[Query]Result{},
+		episodes:   map[seriesKey][]tvdb.Episode{},
+		titles:     map[titleKey][]string{},
+		localized:  map[titleKey]bool{},
+	}
+}
+
+// NewNonInteractiveSearcher creates a searcher that never prompts. It picks the
+// best scored candidate if its score is at least threshold (0..1) and returns an
+// UnresolvedError otherwise.
+func NewNonInteractiveSearcher(tmdbClient tmdb.Client, tvdbClient tvdb.Client, threshold float64) Searcher {
+	return &searcher{
+		tmdbClient:     tmdbClient,
+		tvdbClient:     tvdbClient,
+		nonInteractive: true,
+		threshold:      threshold,
+		cache:          map[Query]Result{},
+		episodes:       map[seriesKey][]tvdb.Episode{},
+		titles:         map[titleKey][]string{},
+		localized:      map[titleKey]bool{},
 	}
 }
 
@@ -45,51 +146,198 @@ func (s *searcher) SearchMovie(query Query) (Result, error) {
 	if v, ok := s.cache[query]; ok {
 		return v, nil
 	}
-	response, err := s.tmdbClient.Search(query.Title, query.Year, 0)
+	if query.Pinned() {
+		result, err := s.pinnedMovie(query)
+		if err != nil {
+			return Result{}, fmt.Errorf("movie lookup failed: %v", err)
+		}
+		s.cache[query] = result
+		return result, nil
+	}
+	result, err := searchVariants(query, s.movies)
 	if err != nil {
 		return Result{}, fmt.Errorf("movie search failed: %v", err)
 	}
-	var result []Result
-	for _, r := range response.Results {
-		result = append(result, Result{r.Title, r.Year()})
-	}
 	if len(result) == 0 {
-		fmt.Printf("no search result for title '%s'\n", query.Title)
-		query, err := s.prompter.AskString(fmt.Sprintf("Search again:"))
+		query, err := s.askForQuery(query)
 		if err != nil {
-			return Result{}, fmt.Errorf("prompt error: %v", err)
+			return Result{}, err
 		}
-		return s.SearchMovie(Query{Title: query})
+		return s.SearchMovie(query)
 	}
-	return s.toSingleResult(query, result)
+	return s.toSingleResult(KindMovie, query, result)
 }
 
 func (s *searcher) SearchTV(query Query) (Result, error) {
 	if v, ok := s.cache[query]; ok {
 		return v, nil
 	}
-	response, err := s.tvdbClient.Search(query.Title)
+	if query.Pinned() {
+		result, err := s.pinnedSeries(query)
+		if err != nil {
+			return Result{}, fmt.Errorf("tv lookup failed: %v", err)
+		}
+		s.cache[query] = result
+		return result, nil
+	}
+	result, err := searchVariants(query, s.series)
 	if err != nil {
 		return Result{}, fmt.Errorf("tv search failed: %v", err)
 	}
+	if len(result) == 0 {
+		query, err := s.askForQuery(query)
+		if err != nil {
+			return Result{}, err
+		}
+		return s.SearchTV(query)
+	}
+	return s.toSingleResult(KindTV, query, result)
+}
+
+// searchVariants searches for the title of query and, as long as that
+// yields nothing, for its variants as returned by parser.TitleVariants.
+func searchVariants(query Query, search func(Query) ([]Result, error)) ([]Result, error) {
+	titles := append([]string{query.Title}, parser.TitleVariants(query.Title)...)
+	for _, title := range titles {
+		result, err := search(Query{Title: title, Year: query.Year})
+		if err != nil || len(result) > 0 {
+			return result, err
+		}
+	}
+	return nil, nil
+}
+
+// pinnedMovie looks up the movie query is pinned to on TMDB,
+// by its TMDB id or else by its IMDb id.
+func (s *searcher) pinnedMovie(query Query) (Result, error) {
+	id := query.ID
+	if id == 0 {
+		response, err := s.tmdbClient.FindByIMDbID(query.IMDbID)
+		if err != nil {
+			return Result{}, err
+		}
+		if len(response.MovieResults) == 0 {
+			return Result{}, fmt.Errorf("no movie with imdb id %s", query.IMDbID)
+		}
+		id = response.MovieResults[0].ID
+	}
+	m, err := s.tmdbClient.Movie(id)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{ID: m.ID, Title: m.Title, Year: m.Year(), Popularity: m.Popularity, Pinned: true}, nil
+}
+
+func (s *searcher) movies(query Query) ([]Result, error) {
+	response, err := s.tmdbClient.Search(query.Title, query.Year, 0)
+	if err != nil {
+		return nil, err
+	}
 	var result []Result
 	for _, r := range response.Results {
-		result = append(result, Result{r.Title, r.Year()})
+		result = append(result, Result{ID: r.ID, Title: r.Title, Year: r.Year(), Popularity: r.Popularity})
+		key := titleKey{kind: KindMovie, id: r.ID}
+		if _, ok := s.titles[key]; !ok && r.OriginalTitle != "" && r.OriginalTitle != r.Title {
+			s.titles[key] = []string{r.OriginalTitle}
+		}
 	}
-	if len(result) == 0 {
-		fmt.Printf("no search result for title '%s'\n", query.Title)
-		query, err := s.prompter.AskString(fmt.Sprintf("Search again:"))
-		if err != nil {
-			return Result{}, fmt.Errorf("prompt error: %v", err)
+	return result, nil
+}
+
+// EpisodeTitle looks up the title of an episode of the given series on its provider.
+// An episode that is not known yields an empty title.
+func (s *searcher) EpisodeTitle(series Result, season, episode int) (string, error) {
+	if series.ID == 0 {
+		return "", nil
+	}
+	episodes, err := s.seriesEpisodes(series)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range episodes {
+		if e.AiredSeason == season && e.AiredEpisodeNumber == episode {
+			return e.Title, nil
+		}
+	}
+	return "", nil
+}
+
+// AbsoluteEpisode maps an episode number counted over all seasons to the
+// aired season and episode, using the absolute order of the series on its provider.
+// An episode that is not known yields an UnresolvedError.
+func (s *searcher) AbsoluteEpisode(series Result, absolute int) (int, int, error) {
+	unresolved := &UnresolvedError{
+		Query:  Query{Title: series.Title, Year: series.Year},
+		Reason: fmt.Sprintf("no episode with absolute number %d", absolute),
+	}
+	if series.ID == 0 {
+		return 0, 0, unresolved
+	}
+	episodes, err := s.seriesEpisodes(series)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, e := range episodes {
+		// Specials share absolute numbers with regular episodes at times.
+		if e.AbsoluteNumber == absolute && e.AiredSeason > 0 {
+			return e.AiredSeason, e.AiredEpisodeNumber, nil
 		}
-		return s.SearchTV(Query{Title: query})
 	}
-	return s.toSingleResult(query, result)
+	return 0, 0, unresolved
+}
+
+// AiredEpisode finds the season and episode of a daily show that aired on
+// the given date. An episode that is not known yields an UnresolvedError.
+func (s *searcher) AiredEpisode(series Result, date time.Time) (int, int, error) {
+	unresolved := &UnresolvedError{
+		Query:  Query{Title: series.Title, Year: series.Year},
+		Reason: fmt.Sprintf("no episode aired on %s", date.Format("2006-01-02")),
+	}
+	if series.ID == 0 {
+		return 0, 0, unresolved
+	}
+	episodes, err := s.seriesEpisodes(series)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, e := range episodes {
+		if e.FirstAired == date.Format("2006-01-02") && e.AiredSeason > 0 {
+			return e.AiredSeason, e.AiredEpisodeNumber, nil
+		}
+	}
+	return 0, 0, unresolved
+}
+
+func (s *searcher) askForQuery(query Query) (Query, error) {
+	if s.nonInteractive {
+		return Query{}, &UnresolvedError{Query: query, Reason: "no search result"}
+	}
+	fmt.Printf("no search result for title '%s'\n", query.Title)
+	title, err := s.prompter.AskString(fmt.Sprintf("Search again:"))
+	if err != nil {
+		return Query{}, fmt.Errorf("prompt error: %v", err)
+	}
+	return Query{Title: title}, nil
 }
 
-func (s *searcher) toSingleResult(query Query, results []Result) (Result, error) {
+func (s *searcher) toSingleResult(kind string, query Query, results []Result) (Result, error) {
 	var result Result
-	if len(results) > 1 {
+	if r, ok := s.rememberedChoice(kind, query, results); ok {
+		result = r
+	} else if s.nonInteractive {
+		best, score := bestCandidate(query, results, s.knownTitles(kind))
+		if score < s.threshold && kind == KindMovie {
+			if err := s.localizeTitles(results); err != nil {
+				return result, err
+			}
+			best, score = bestCandidate(query, results, s.knownTitles(kind))
+		}
+		if score < s.threshold {
+			reason := fmt.Sprintf("best candidate %s (%d) scored %.2f, below threshold %.2f", best.Title, best.Year, score, s.threshold)
+			return result, &UnresolvedError{Query: query, Reason: reason}
+		}
+		result = best
+	} else if len(results) > 1 {
 		choices := []string{fmt.Sprintf("Multiple results found online for %s, pick one of:", query.Title)}
 		for i, r := range results {
 			choices = append(choices, fmt.Sprintf("[%d] %s (%d)", i+1, r.Title, r.Year))
@@ -99,9 +347,102 @@ func (s *searcher) toSingleResult(query Query, results []Result) (Result, error)
 			return result, fmt.Errorf("prompt error: %v", err)
 		}
 		result = results[i-1]
+		if s.choices != nil {
+			if err := s.choices.Remember(kind, query, result); err != nil {
+				return result, fmt.Errorf("could not remember choice: %v", err)
+			}
+		}
 	} else {
 		result = results[0]
 	}
 	s.cache[query] = result
 	return result, nil
 }
+
+// rememberedChoice returns the result the user chose for query
+// in an earlier run, if it is still among the results.
+func (s *searcher) rememberedChoice(kind string, query Query, results []Result) (Result, bool) {
+	if s.choices == nil || len(results) < 2 {
+		return Result{}, false
+	}
+	choice, ok := s.choices.Choice(kind, query)
+	if !ok {
+		return Result{}, false
+	}
+	for _, r := range results {
+		if r.Title == choice.Title && r.Year == choice.Year {
+			return r, true
+		}
+	}
+	return Result{}, false
+}
+
+// localizedCandidates is the number of results whose localized
+// titles are looked up if none of them matches well enough.
+const localizedCandidates = 5
+
+// localizeTitles looks up the alternative titles and translations of the
+// first results, e.g. Stirb langsam for Die Hard in the german release name.
+func (s *searcher) localizeTitles(results []Result) error {
+	if len(results) > localizedCandidates {
+		results = results[:localizedCandidates]
+	}
+	for _, r := range results {
+		key := titleKey{kind: KindMovie, id: r.ID}
+		if r.ID == 0 || s.localized[key] {
+			continue
+		}
+		alternatives, err := s.tmdbClient.AlternativeTitles(r.ID)
+		if err != nil {
+			return fmt.Errorf("alternative titles lookup failed: %v", err)
+		}
+		translations, err := s.tmdbClient.Translations(r.ID)
+		if err != nil {
+			return fmt.Errorf("translations lookup failed: %v", err)
+		}
+		titles := s.titles[key]
+		for _, a := range alternatives.Titles {
+			titles = append(titles, a.Title)
+		}
+		for _, t := range translations.Translations {
+			if t.Data.Title != "" {
+				titles = append(titles, t.Data.Title)
+			}
+		}
+		s.titles[key] = titles
+		s.localized[key] = true
+	}
+	return nil
+}
+
+// knownTitles returns a func that gives the titles of a result
+// of the given kind besides its own.
+func (s *searcher) knownTitles(kind string) func(Result) []string {
+	return func(r Result) []string {
+		return s.titles[titleKey{kind: kind, id: r.ID}]
+	}
+}
+
+// bestCandidate returns the best scored of results, each is scored by
+// its own title and the titles given by others, whichever matches best.
+func bestCandidate(query Query, results []Result, others func(Result) []string) (Result, float64) {
+	maxPopularity := 0.0
+	for _, r := range results {
+		if r.Popularity > maxPopularity {
+			maxPopularity = r.Popularity
+		}
+	}
+	var best Result
+	bestScore := -1.0
+	for _, r := range results {
+		titles := append([]string{r.Title}, others(r)...)
+		for _, title := range titles {
+			scored := r
+			scored.Title = title
+			if score := Score(query, scored, maxPopularity); score > bestScore {
+				best, bestScore = r, score
+			}
+		}
+	}
+	return best, bestScore
+}
@@ -0,0 +1,289 @@
+package search_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/florianehmke/plexname/mock"
+	"github.com/florianehmke/plexname/search"
+	"github.com/florianehmke/plexname/tmdb"
+	"github.com/florianehmke/plexname/tvdb"
+)
+
+func TestScore(t *testing.T) {
+	query := search.Query{Title: "the matrix", Year: 1999}
+	exact := search.Score(query, search.Result{Title: "The Matrix", Year: 1999, Popularity: 10}, 10)
+	wrongYear := search.Score(query, search.Result{Title: "The Matrix", Year: 2021, Popularity: 10}, 10)
+	sequel := search.Score(query, search.Result{Title: "The Matrix Reloaded", Year: 2003, Popularity: 10}, 10)
+
+	if exact < 0.99 {
+		t.Errorf("expected an exact match to score ~1, got %.2f", exact)
+	}
+	if wrongYear >= exact {
+		t.Errorf("expected a year mismatch to lower the score")
+	}
+	if sequel >= wrongYear {
+		t.Errorf("expected a different title to score lower than a different year")
+	}
+}
+
+// TestScoreUnknownCriteria makes sure an exact title is good enough for
+// results without year and popularity, as TVDB returns them at times.
+func TestScoreUnknownCriteria(t *testing.T) {
+	query := search.Query{Title: "doctor who"}
+	if score := search.Score(query, search.Result{Title: "Doctor Who"}, 0); score < 0.99 {
+		t.Errorf("expected an exact title without year and popularity to score ~1, got %.2f", score)
+	}
+
+	s := search.NewNonInteractiveSearcher(nil, mock.NewMockTVDB(tvdb.SearchResponse{Results: []tvdb.SearchResult{
+		{ID: 78804, Title: "Doctor Who"},
+	}}, nil), 0.8)
+	if r, err := s.SearchTV(query); err != nil || r.ID != 78804 {
+		t.Errorf("expected Doctor Who, got %+v (%v)", r, err)
+	}
+}
+
+func TestNonInteractiveSearch(t *testing.T) {
+	tmdbClient := mock.NewMockTMDB(tmdb.SearchResponse{Results: []tmdb.SearchResult{
+		{Title: "The Matrix Reloaded", ReleaseDate: "2003-05-15", Popularity: 30},
+		{Title: "The Matrix", ReleaseDate: "1999-03-30", Popularity: 40},
+	}}, nil)
+	s := search.NewNonInteractiveSearcher(tmdbClient, nil, 0.8)
+
+	r, err := s.SearchMovie(search.Query{Title: "the matrix", Year: 1999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Title != "The Matrix" || r.Year != 1999 {
+		t.Errorf("expected The Matrix (1999), got %s (%d)", r.Title, r.Year)
+	}
+
+	_, err = s.SearchMovie(search.Query{Title: "matrix revolutions"})
+	if _, ok := err.(*search.UnresolvedError); !ok {
+		t.Errorf("expected an unresolved error, got %v", err)
+	}
+
+	empty := search.NewNonInteractiveSearcher(nil, mock.NewMockTVDB(tvdb.SearchResponse{}, nil), 0.8)
+	_, err = empty.SearchTV(search.Query{Title: "unknown show"})
+	if _, ok := err.(*search.UnresolvedError); !ok {
+		t.Errorf("expected an unresolved error, got %v", err)
+	}
+}
+
+// titleTMDB only knows movies by their exact title.
+type titleTMDB struct {
+	tmdb.Client
+	results map[string][]tmdb.SearchResult
+	queries []string
+}
+
+func (c *titleTMDB) Search(query string, year int, page int) (*tmdb.SearchResponse, error) {
+	c.queries = append(c.queries, query)
+	return &tmdb.SearchResponse{Results: c.results[query]}, nil
+}
+
+func TestSearchTitleVariants(t *testing.T) {
+	tmdbClient := &titleTMDB{results: map[string][]tmdb.SearchResult{
+		"amelie": {{Title: "Amélie", ReleaseDate: "2001-04-25", Popularity: 20}},
+	}}
+	s := search.NewNonInteractiveSearcher(tmdbClient, nil, 0.8)
+
+	r, err := s.SearchMovie(search.Query{Title: "the amélie", Year: 2001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Title != "Amélie" {
+		t.Errorf("expected Amélie, got %s", r.Title)
+	}
+	expected := []string{"the amélie", "the amelie", "amélie", "amelie"}
+	if len(tmdbClient.queries) != len(expected) {
+		t.Fatalf("expected queries %v, got %v", expected, tmdbClient.queries)
+	}
+	for i := range expected {
+		if tmdbClient.queries[i] != expected[i] {
+			t.Errorf("expected queries %v, got %v", expected, tmdbClient.queries)
+		}
+	}
+}
+
+// titleTVDB only knows series by their exact title, like TVDB it
+// answers other searches with a 404.
+type titleTVDB struct {
+	tvdb.Client
+	results map[string][]tvdb.SearchResult
+}
+
+func (c *titleTVDB) Search(query string) (*tvdb.SearchResponse, error) {
+	if len(c.results[query]) == 0 {
+		return nil, &tvdb.NotFoundError{Message: "Resource not found"}
+	}
+	return &tvdb.SearchResponse{Results: c.results[query]}, nil
+}
+
+func TestSearchTitleVariants_TVDBNotFound(t *testing.T) {
+	tvdbClient := &titleTVDB{results: map[string][]tvdb.SearchResult{
+		"office": {{ID: 73244, Title: "Office", FirstAired: "2005-03-24"}},
+	}}
+	s := search.NewNonInteractiveSearcher(nil, tvdbClient, 0.8)
+
+	r, err := s.SearchTV(search.Query{Title: "the office"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 73244 {
+		t.Errorf("expected the variant to be found, got %+v", r)
+	}
+}
+
+func TestPinnedSearch(t *testing.T) {
+	tmdbClient := mock.NewMockTMDB(tmdb.SearchResponse{Results: []tmdb.SearchResult{
+		{ID: 278, Title: "The Shawshank Redemption", ReleaseDate: "1994-09-23"},
+	}}, nil)
+	tvdbClient := mock.NewMockTVDB(tvdb.SearchResponse{Results: []tvdb.SearchResult{
+		{ID: 76107, Title: "Doctor Who", FirstAired: "1963-11-23"},
+		{ID: 78804, Title: "Doctor Who", FirstAired: "2005-03-26"},
+	}}, nil)
+	s := search.NewSearcher(tmdbClient, tvdbClient, mock.NewMockPrompter(nil, nil, nil))
+
+	r, err := s.SearchTV(search.Query{Title: "doctor who", ID: 78804})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 78804 || r.Year != 2005 || !r.Pinned {
+		t.Errorf("expected pinned Doctor Who (2005), got %+v", r)
+	}
+
+	r, err = s.SearchMovie(search.Query{Title: "wrong title", IMDbID: "tt0111161"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 278 || r.Title != "The Shawshank Redemption" || r.Year != 1994 {
+		t.Errorf("expected The Shawshank Redemption (1994), got %+v", r)
+	}
+
+	if _, err := s.SearchTV(search.Query{Title: "doctor who", ID: 1}); err == nil {
+		t.Error("expected an error for an unknown id")
+	}
+}
+
+func TestPinnedSearch_TVProviders(t *testing.T) {
+	tmdbClient := mock.NewMockTMDBWithTV(tmdb.TVSearchResponse{Results: []tmdb.TVSearchResult{
+		{ID: 57243, Name: "Doctor Who", FirstAirDate: "2005-03-26"},
+	}}, nil, nil)
+	tvdbClient := mock.NewMockTVDB(tvdb.SearchResponse{}, errors.New("tvdb is down"))
+	s := search.NewNonInteractiveSearcher(tmdbClient, tvdbClient, 0.8)
+
+	// TVDB errors for the IMDb id, TMDB is tried next.
+	r, err := s.SearchTV(search.Query{Title: "doctor who", IMDbID: "tt0436992"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 57243 || r.Provider != search.ProviderTMDB || !r.Pinned {
+		t.Errorf("expected pinned Doctor Who on tmdb, got %+v", r)
+	}
+
+	// A TMDB id is looked up on TMDB, even if TVDB comes first.
+	r, err = s.SearchTV(search.Query{Title: "doctor who", ID: 57243, Provider: search.ProviderTMDB})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 57243 || r.Provider != search.ProviderTMDB {
+		t.Errorf("expected pinned Doctor Who on tmdb, got %+v", r)
+	}
+
+	// TMDB can not look up a TVDB id.
+	if _, err := s.SearchTV(search.Query{Title: "doctor who", ID: 78804}); err == nil {
+		t.Error("expected an error for a tvdb id while tvdb is down")
+	}
+}
+
+func TestLocalizedSearch(t *testing.T) {
+	tmdbClient := mock.NewMockTMDBWithTitles(tmdb.SearchResponse{Results: []tmdb.SearchResult{
+		{ID: 562, Title: "Die Hard", ReleaseDate: "1988-07-15", Popularity: 40},
+		{ID: 387, Title: "The Boat", OriginalTitle: "Das Boot", ReleaseDate: "1981-09-16", Popularity: 20},
+	}}, map[int][]tmdb.AlternativeTitle{
+		562: {{Country: "DE", Title: "Stirb langsam"}},
+	}, nil)
+	s := search.NewNonInteractiveSearcher(tmdbClient, nil, 0.8)
+
+	tests := []struct {
+		query    search.Query
+		expected string
+	}{
+		{search.Query{Title: "stirb langsam", Year: 1988}, "Die Hard"},
+		{search.Query{Title: "das boot", Year: 1981}, "The Boat"},
+	}
+	for _, tc := range tests {
+		r, err := s.SearchMovie(tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Title != tc.expected {
+			t.Errorf("expected %s for %s, got %s", tc.expected, tc.query.Title, r.Title)
+		}
+	}
+}
+
+// TestLocalizedSearch_TVKeepsOwnTitles makes sure a series does not match
+// by the titles of the movie that happens to have the same id.
+func TestLocalizedSearch_TVKeepsOwnTitles(t *testing.T) {
+	tmdbClient := mock.NewMockTMDB(tmdb.SearchResponse{Results: []tmdb.SearchResult{
+		{ID: 387, Title: "The Boat", OriginalTitle: "Das Boot", ReleaseDate: "1981-09-16", Popularity: 20},
+	}}, nil)
+	tvdbClient := mock.NewMockTVDB(tvdb.SearchResponse{Results: []tvdb.SearchResult{
+		{ID: 387, Title: "Some Other Show", FirstAired: "1981-01-01"},
+	}}, nil)
+	s := search.NewNonInteractiveSearcher(tmdbClient, tvdbClient, 0.8)
+
+	if _, err := s.SearchMovie(search.Query{Title: "das boot", Year: 1981}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := s.SearchTV(search.Query{Title: "das boot"})
+	if _, ok := err.(*search.UnresolvedError); !ok {
+		t.Errorf("expected an unresolved error, got %v", err)
+	}
+}
+
+func TestTVProvider(t *testing.T) {
+	tmdbClient := mock.NewMockTMDBWithTV(tmdb.TVSearchResponse{Results: []tmdb.TVSearchResult{
+		{ID: 57243, Name: "Doctor Who", FirstAirDate: "2005-03-26", Popularity: 50},
+	}}, []tmdb.Season{
+		{SeasonNumber: 0, Episodes: []tmdb.Episode{{SeasonNumber: 0, EpisodeNumber: 1, Name: "The Christmas Invasion"}}},
+		{SeasonNumber: 1, Episodes: []tmdb.Episode{
+			{SeasonNumber: 1, EpisodeNumber: 1, Name: "Rose"},
+			{SeasonNumber: 1, EpisodeNumber: 2, Name: "The End of the World"},
+		}},
+		{SeasonNumber: 2, Episodes: []tmdb.Episode{{SeasonNumber: 2, EpisodeNumber: 1, Name: "New Earth", AirDate: "2006-04-15"}}},
+	}, nil)
+	brokenTVDB := mock.NewMockTVDB(tvdb.SearchResponse{}, errors.New("tvdb is down"))
+
+	for _, provider := range []string{search.ProviderTMDB, search.ProviderTVDB} {
+		s := search.UseTVProvider(search.NewSearcher(tmdbClient, brokenTVDB, mock.NewMockPrompter(nil, nil, nil)), provider)
+		series, err := s.SearchTV(search.Query{Title: "doctor who", Year: 2005})
+		if err != nil {
+			t.Fatalf("%s: %v", provider, err)
+		}
+		if series.ID != 57243 || series.Provider != search.ProviderTMDB {
+			t.Fatalf("%s: expected Doctor Who on tmdb, got %+v", provider, series)
+		}
+
+		title, err := s.EpisodeTitle(series, 1, 2)
+		if err != nil || title != "The End of the World" {
+			t.Errorf("%s: expected The End of the World, got %s (%v)", provider, title, err)
+		}
+		season, episode, err := s.AbsoluteEpisode(series, 3)
+		if err != nil || season != 2 || episode != 1 {
+			t.Errorf("%s: expected S02E01 for absolute 3, got S%02dE%02d (%v)", provider, season, episode, err)
+		}
+		date, _ := time.Parse("2006-01-02", "2006-04-15")
+		season, episode, err = s.AiredEpisode(series, date)
+		if err != nil || season != 2 || episode != 1 {
+			t.Errorf("%s: expected S02E01 for 2006-04-15, got S%02dE%02d (%v)", provider, season, episode, err)
+		}
+	}
+
+	if _, err := search.ParseTVProvider("imdb"); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
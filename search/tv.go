@@ -0,0 +1,230 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/florianehmke/plexname/log"
+	"github.com/florianehmke/plexname/tvdb"
+)
+
+// TV providers, as used by UseTVProvider.
+const (
+	ProviderTVDB = "tvdb"
+	ProviderTMDB = "tmdb"
+)
+
+// ParseTVProvider checks the name of a TV provider, empty means TVDB.
+func ParseTVProvider(s string) (string, error) {
+	switch s {
+	case "", ProviderTVDB:
+		return ProviderTVDB, nil
+	case ProviderTMDB:
+		return ProviderTMDB, nil
+	}
+	return "", fmt.Errorf("unknown tv provider: %s", s)
+}
+
+// UseTVProvider makes s search for series on the given provider and
+// fall back to the other one if that fails, see ParseTVProvider.
+// The episodes of a series are looked up where it was found.
+func UseTVProvider(s Searcher, provider string) Searcher {
+	if impl, ok := s.(*searcher); ok {
+		impl.tvProvider = provider
+	}
+	return s
+}
+
+// seriesKey identifies a series across providers.
+type seriesKey struct {
+	provider string
+	id       int
+}
+
+// tvProviders returns the providers to search for series in order,
+// the main one first. Providers without a client are left out.
+func (s *searcher) tvProviders() []string {
+	providers := []string{ProviderTVDB, ProviderTMDB}
+	if s.tvProvider == ProviderTMDB {
+		providers = []string{ProviderTMDB, ProviderTVDB}
+	}
+	var available []string
+	for _, p := range providers {
+		if (p == ProviderTVDB && s.tvdbClient != nil) || (p == ProviderTMDB && s.tmdbClient != nil) {
+			available = append(available, p)
+		}
+	}
+	return available
+}
+
+func (s *searcher) series(query Query) ([]Result, error) {
+	providers := s.tvProviders()
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no tv provider")
+	}
+	var err error
+	for i, p := range providers {
+		var result []Result
+		if result, err = s.seriesOn(p, query); err == nil {
+			return result, nil
+		}
+		if i < len(providers)-1 {
+			log.Warnf("%s search for %s failed, trying %s: %v", p, query.Title, providers[i+1], err)
+		}
+	}
+	return nil, err
+}
+
+func (s *searcher) seriesOn(provider string, query Query) ([]Result, error) {
+	var result []Result
+	if provider == ProviderTMDB {
+		response, err := s.tmdbClient.SearchTV(query.Title, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range response.Results {
+			result = append(result, Result{ID: r.ID, Title: r.Name, Year: r.Year(), Popularity: r.Popularity, Provider: ProviderTMDB})
+		}
+		return result, nil
+	}
+	response, err := s.tvdbClient.Search(query.Title)
+	if tvdb.IsNotFound(err) {
+		return nil, nil // TVDB's answer to a search without matches
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range response.Results {
+		result = append(result, Result{ID: r.ID, Title: r.Title, Year: r.Year(), Provider: ProviderTVDB})
+	}
+	return result, nil
+}
+
+// pinnedSeries looks up the series query is pinned to on the TV
+// providers in order. A provider is asked if the id is one of its
+// own or if an IMDb id is known, the next one is tried if that fails.
+func (s *searcher) pinnedSeries(query Query) (Result, error) {
+	provider := query.Provider
+	if provider == "" {
+		provider = ProviderTVDB
+	}
+	var providers []string
+	for _, p := range s.tvProviders() {
+		if (query.ID != 0 && p == provider) || query.IMDbID != "" {
+			providers = append(providers, p)
+		}
+	}
+	if len(providers) == 0 {
+		return Result{}, fmt.Errorf("no tv provider for %s id %d", provider, query.ID)
+	}
+	var err error
+	for i, p := range providers {
+		id := 0
+		if p == provider {
+			id = query.ID
+		}
+		var result Result
+		if result, err = s.pinnedSeriesOn(p, id, query.IMDbID); err == nil {
+			return result, nil
+		}
+		if i < len(providers)-1 {
+			log.Warnf("%s lookup for %s failed, trying %s: %v", p, query.Title, providers[i+1], err)
+		}
+	}
+	return Result{}, err
+}
+
+// pinnedSeriesOn looks up a series on the given provider,
+// by its id there or else by its IMDb id.
+func (s *searcher) pinnedSeriesOn(provider string, id int, imdbID string) (Result, error) {
+	if provider == ProviderTMDB {
+		if id == 0 {
+			response, err := s.tmdbClient.FindByIMDbID(imdbID)
+			if err != nil {
+				return Result{}, err
+			}
+			if len(response.TVResults) == 0 {
+				return Result{}, fmt.Errorf("no series with imdb id %s", imdbID)
+			}
+			id = response.TVResults[0].ID
+		}
+		tv, err := s.tmdbClient.TV(id)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{ID: tv.ID, Title: tv.Name, Year: tv.Year(), Popularity: tv.Popularity, Provider: ProviderTMDB, Pinned: true}, nil
+	}
+	if id == 0 {
+		response, err := s.tvdbClient.SearchByIMDbID(imdbID)
+		if err != nil {
+			return Result{}, err
+		}
+		if len(response.Results) == 0 {
+			return Result{}, fmt.Errorf("no series with imdb id %s", imdbID)
+		}
+		id = response.Results[0].ID
+	}
+	series, err := s.tvdbClient.Series(id)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{ID: series.ID, Title: series.Title, Year: series.Year(), Provider: ProviderTVDB, Pinned: true}, nil
+}
+
+func (s *searcher) seriesEpisodes(series Result) ([]tvdb.Episode, error) {
+	key := seriesKey{provider: series.Provider, id: series.ID}
+	if key.provider == "" {
+		key.provider = ProviderTVDB
+	}
+	if episodes, ok := s.episodes[key]; ok {
+		return episodes, nil
+	}
+	var episodes []tvdb.Episode
+	var err error
+	if key.provider == ProviderTMDB {
+		episodes, err = s.tmdbEpisodes(series.ID)
+	} else {
+		episodes, err = s.tvdbClient.Episodes(series.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("episode lookup failed: %v", err)
+	}
+	s.episodes[key] = episodes
+	return episodes, nil
+}
+
+// tmdbEpisodes looks up all episodes of a TV show on TMDB, season by
+// season. They are converted to the TVDB shape the lookups are written
+// for, the absolute numbers count the episodes of the regular seasons.
+func (s *searcher) tmdbEpisodes(id int) ([]tvdb.Episode, error) {
+	tv, err := s.tmdbClient.TV(id)
+	if err != nil {
+		return nil, err
+	}
+	seasons := tv.Seasons
+	sort.Slice(seasons, func(i, j int) bool { return seasons[i].SeasonNumber < seasons[j].SeasonNumber })
+
+	var episodes []tvdb.Episode
+	absolute := 0
+	for _, summary := range seasons {
+		season, err := s.tmdbClient.Season(id, summary.SeasonNumber)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range season.Episodes {
+			episode := tvdb.Episode{
+				ID:                 e.ID,
+				AiredSeason:        e.SeasonNumber,
+				AiredEpisodeNumber: e.EpisodeNumber,
+				Title:              e.Name,
+				FirstAired:         e.AirDate,
+			}
+			if e.SeasonNumber > 0 {
+				absolute++
+				episode.AbsoluteNumber = absolute
+			}
+			episodes = append(episodes, episode)
+		}
+	}
+	return episodes, nil
+}
@@ -0,0 +1,88 @@
+package tmdb
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	movieEndpoint             = "/movie/%d?api_key=%s&language=%s"
+	findEndpoint              = "/find/%s?api_key=%s&language=%s&external_source=imdb_id"
+	alternativeTitlesEndpoint = "/movie/%d/alternative_titles?api_key=%s"
+	translationsEndpoint      = "/movie/%d/translations?api_key=%s"
+)
+
+// Movie details from TMDB.
+type Movie struct {
+	SearchResult
+	IMDbID string `json:"imdb_id"` // e.g. tt0111161
+}
+
+// FindResponse from TMDB, the movies and TV shows with an external id.
+type FindResponse struct {
+	MovieResults []SearchResult   `json:"movie_results"`
+	TVResults    []TVSearchResult `json:"tv_results"`
+}
+
+// AlternativeTitlesResponse from TMDB, the titles a movie is known by in other countries.
+type AlternativeTitlesResponse struct {
+	ID     int                `json:"id"`
+	Titles []AlternativeTitle `json:"titles"`
+}
+
+type AlternativeTitle struct {
+	Country string `json:"iso_3166_1"` // e.g. DE
+	Title   string `json:"title"`      // e.g. Stirb langsam
+	Type    string `json:"type"`       // e.g. working title, often empty
+}
+
+// TranslationsResponse from TMDB, the metadata of a movie in other languages.
+type TranslationsResponse struct {
+	ID           int           `json:"id"`
+	Translations []Translation `json:"translations"`
+}
+
+type Translation struct {
+	Country     string `json:"iso_3166_1"`   // e.g. DE
+	Language    string `json:"iso_639_1"`    // e.g. de
+	EnglishName string `json:"english_name"` // e.g. German
+	Data        struct {
+		Title string `json:"title"` // empty if it is the original title
+	} `json:"data"`
+}
+
+// Movie looks up the details of a movie on TMDB.
+func (s *client) Movie(id int) (*Movie, error) {
+	var result Movie
+	if err := s.get(fmt.Sprintf(s.baseURL+movieEndpoint, id, s.apiKey, url.QueryEscape(s.language)), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FindByIMDbID looks up the movies and TV shows with the given IMDb id on TMDB.
+func (s *client) FindByIMDbID(imdbID string) (*FindResponse, error) {
+	var result FindResponse
+	if err := s.get(fmt.Sprintf(s.baseURL+findEndpoint, url.PathEscape(imdbID), s.apiKey, url.QueryEscape(s.language)), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AlternativeTitles looks up the titles of a movie in other countries on TMDB.
+func (s *client) AlternativeTitles(id int) (*AlternativeTitlesResponse, error) {
+	var result AlternativeTitlesResponse
+	if err := s.get(fmt.Sprintf(s.baseURL+alternativeTitlesEndpoint, id, s.apiKey), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Translations looks up the translated metadata of a movie on TMDB.
+func (s *client) Translations(id int) (*TranslationsResponse, error) {
+	var result TranslationsResponse
+	if err := s.get(fmt.Sprintf(s.baseURL+translationsEndpoint, id, s.apiKey), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -2,12 +2,11 @@ package tmdb
 
 import (
 	"fmt"
-	"net/http"
 	"net/url"
 	"strconv"
 )
 
-const searchEndpoint = "/search/%s?api_key=%s&language=en-US"
+const searchEndpoint = "/search/%s?api_key=%s&language=%s"
 
 // SearchResponse from TMDB.
 type SearchResponse struct {
@@ -18,8 +17,11 @@ type SearchResponse struct {
 }
 
 type SearchResult struct {
-	ReleaseDate string `json:"release_date"` // e.g. 2014-03-20
-	Title       string `json:"title"`
+	ID            int     `json:"id"`
+	ReleaseDate   string  `json:"release_date"`   // e.g. 2014-03-20
+	Title         string  `json:"title"`          // in the language of the client
+	OriginalTitle string  `json:"original_title"` // e.g. Das Boot
+	Popularity    float64 `json:"popularity"`
 }
 
 func (sr *SearchResult) Year() int {
@@ -35,7 +37,7 @@ func (sr *SearchResult) Year() int {
 
 // Search for movies on TMDB.
 func (s *client) Search(query string, year int, page int) (*SearchResponse, error) {
-	reqURL := fmt.Sprintf(s.baseURL+searchEndpoint, "movie", s.apiKey)
+	reqURL := fmt.Sprintf(s.baseURL+searchEndpoint, "movie", s.apiKey, url.QueryEscape(s.language))
 
 	// Build the query string.
 	v := url.Values{}
@@ -51,17 +53,9 @@ func (s *client) Search(query string, year int, page int) (*SearchResponse, erro
 		reqURL = reqURL + "&" + qs
 	}
 
-	// Do the request.
-	s.ensureRateLimit()
-	resp, err := http.Get(reqURL)
-	if err != nil {
-		return nil, fmt.Errorf("could not get tmdb details: %v", err)
-	}
-	defer resp.Body.Close()
-
 	var result SearchResponse
-	if err := unmarshalResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal of response failed: %v", err)
+	if err := s.get(reqURL, &result); err != nil {
+		return nil, err
 	}
 	return &result, nil
 }
@@ -11,25 +11,47 @@ import (
 
 const BaseURL = "https://api.themoviedb.org/3"
 
+// DefaultLanguage of titles and other metadata.
+const DefaultLanguage = "en-US"
+
 // client is the TMDB service struct.
 type client struct {
 	httpClient *http.Client
 	baseURL    string
 	apiKey     string
+	language   string // e.g. de-DE
 
 	throttle chan time.Time
 }
 
 type Client interface {
 	Search(query string, year int, page int) (*SearchResponse, error)
+	Movie(id int) (*Movie, error)
+	FindByIMDbID(imdbID string) (*FindResponse, error)
+	AlternativeTitles(id int) (*AlternativeTitlesResponse, error)
+	Translations(id int) (*TranslationsResponse, error)
+	SearchTV(query string, year int, page int) (*TVSearchResponse, error)
+	TV(id int) (*TV, error)
+	Season(tvID int, season int) (*Season, error)
 }
 
-// NewClient creates a new TMDB service.
+// NewClient creates a new TMDB service with metadata in the default language.
 func NewClient(baseURL string, apiKey string) Client {
+	return NewClientWithLanguage(baseURL, apiKey, DefaultLanguage)
+}
+
+// NewClientWithLanguage creates a new TMDB service with metadata in the given
+// language, an IETF tag such as de-DE. Search results match titles in any
+// language but are given in this one.
+func NewClientWithLanguage(baseURL string, apiKey string, language string) Client {
+	if language == "" {
+		language = DefaultLanguage
+	}
 	service := &client{
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 		baseURL:    baseURL,
 		apiKey:     apiKey,
+		language:   language,
 	}
 	service.startRateLimiter()
 	return service
@@ -54,6 +76,21 @@ func (s *client) startRateLimiter() {
 	}()
 }
 
+// get does a rate limited get request and unmarshals the response into result.
+func (s *client) get(reqURL string, result interface{}) error {
+	s.ensureRateLimit()
+	resp, err := s.httpClient.Get(reqURL)
+	if err != nil {
+		return fmt.Errorf("could not get tmdb details: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := unmarshalResponse(resp, result); err != nil {
+		return fmt.Errorf("unmarshal of response failed: %v", err)
+	}
+	return nil
+}
+
 type apiError struct {
 	StatusMessage string `json:"status_message"`
 	StatusCode    int    `json:"status_code"`
@@ -68,3 +68,111 @@ func TestSearchResult_Year(t *testing.T) {
 		t.Errorf("expected year to be 2014")
 	}
 }
+
+func TestMovie_Success(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/find/tt0111161?api_key=apiKey&language=en-US&external_source=imdb_id":
+			w.Write([]byte(`{"movie_results": [{"id": 278, "title": "The Shawshank Redemption"}]}`))
+		case "/movie/278?api_key=apiKey&language=en-US":
+			w.Write([]byte(`{"id": 278, "title": "The Shawshank Redemption", "release_date": "1994-09-23", "imdb_id": "tt0111161"}`))
+		default:
+			t.Errorf("Unexpected URI %s", r.RequestURI)
+		}
+	}))
+	defer ts.Close()
+
+	s := tmdb.NewClient(ts.URL, "apiKey")
+	f, err := s.FindByIMDbID("tt0111161")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(f.MovieResults) != 1 || f.MovieResults[0].ID != 278 {
+		t.Fatalf("Expected movie 278, got %v", f.MovieResults)
+	}
+	m, err := s.Movie(f.MovieResults[0].ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if m.Title != "The Shawshank Redemption" || m.Year() != 1994 || m.IMDbID != "tt0111161" {
+		t.Errorf("Expected The Shawshank Redemption (1994), got %+v", m)
+	}
+}
+
+func TestLocalizedTitles_Success(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/search/movie?api_key=apiKey&language=de-DE&query=Die+Hard":
+			w.Write([]byte(`{"results": [{"id": 562, "title": "Stirb langsam", "original_title": "Die Hard"}]}`))
+		case "/movie/562/alternative_titles?api_key=apiKey":
+			w.Write([]byte(`{"id": 562, "titles": [{"iso_3166_1": "DE", "title": "Stirb langsam", "type": ""}]}`))
+		case "/movie/562/translations?api_key=apiKey":
+			w.Write([]byte(`{"id": 562, "translations": [{"iso_3166_1": "FR", "iso_639_1": "fr", "english_name": "French", "data": {"title": "Piège de cristal"}}]}`))
+		default:
+			t.Errorf("Unexpected URI %s", r.RequestURI)
+		}
+	}))
+	defer ts.Close()
+
+	s := tmdb.NewClientWithLanguage(ts.URL, "apiKey", "de-DE")
+	r, err := s.Search("Die Hard", -1, -1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(r.Results) != 1 || r.Results[0].Title != "Stirb langsam" || r.Results[0].OriginalTitle != "Die Hard" {
+		t.Errorf("Expected Stirb langsam, got %v", r.Results)
+	}
+	a, err := s.AlternativeTitles(562)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(a.Titles) != 1 || a.Titles[0].Title != "Stirb langsam" || a.Titles[0].Country != "DE" {
+		t.Errorf("Expected Stirb langsam, got %v", a.Titles)
+	}
+	tr, err := s.Translations(562)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tr.Translations) != 1 || tr.Translations[0].Data.Title != "Piège de cristal" || tr.Translations[0].Language != "fr" {
+		t.Errorf("Expected Piège de cristal, got %v", tr.Translations)
+	}
+}
+
+func TestTV_Success(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/search/tv?api_key=apiKey&language=en-US&query=Doctor+Who":
+			w.Write([]byte(`{"results": [{"id": 57243, "name": "Doctor Who", "first_air_date": "2005-03-26"}]}`))
+		case "/tv/57243?api_key=apiKey&language=en-US":
+			w.Write([]byte(`{"id": 57243, "name": "Doctor Who", "seasons": [{"season_number": 1, "episode_count": 13}]}`))
+		case "/tv/57243/season/1?api_key=apiKey&language=en-US":
+			w.Write([]byte(`{"season_number": 1, "episodes": [{"season_number": 1, "episode_number": 1, "name": "Rose", "air_date": "2005-03-26"}]}`))
+		default:
+			t.Errorf("Unexpected URI %s", r.RequestURI)
+		}
+	}))
+	defer ts.Close()
+
+	s := tmdb.NewClient(ts.URL, "apiKey")
+	r, err := s.SearchTV("Doctor Who", -1, -1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(r.Results) != 1 || r.Results[0].Name != "Doctor Who" || r.Results[0].Year() != 2005 {
+		t.Fatalf("Expected Doctor Who (2005), got %v", r.Results)
+	}
+	tv, err := s.TV(r.Results[0].ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tv.Seasons) != 1 || tv.Seasons[0].EpisodeCount != 13 {
+		t.Fatalf("Expected one season of 13 episodes, got %v", tv.Seasons)
+	}
+	season, err := s.Season(tv.ID, tv.Seasons[0].SeasonNumber)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(season.Episodes) != 1 || season.Episodes[0].Name != "Rose" {
+		t.Errorf("Expected Rose, got %v", season.Episodes)
+	}
+}
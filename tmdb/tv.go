@@ -0,0 +1,102 @@
+package tmdb
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	tvEndpoint     = "/tv/%d?api_key=%s&language=%s"
+	seasonEndpoint = "/tv/%d/season/%d?api_key=%s&language=%s"
+)
+
+// TVSearchResponse from TMDB.
+type TVSearchResponse struct {
+	Page         int              `json:"page"`
+	Results      []TVSearchResult `json:"results"`
+	TotalResults int              `json:"total_results"`
+	TotalPages   int              `json:"total_pages"`
+}
+
+type TVSearchResult struct {
+	ID           int     `json:"id"`
+	FirstAirDate string  `json:"first_air_date"` // e.g. 2005-03-26
+	Name         string  `json:"name"`           // in the language of the client
+	OriginalName string  `json:"original_name"`
+	Popularity   float64 `json:"popularity"`
+}
+
+func (sr *TVSearchResult) Year() int {
+	year := 0
+	if sr.FirstAirDate != "" && len(sr.FirstAirDate) >= 4 {
+		if y, err := strconv.Atoi(sr.FirstAirDate[:4]); err == nil {
+			year = y
+		}
+	}
+	return year
+}
+
+// TV details from TMDB.
+type TV struct {
+	TVSearchResult
+	Seasons []SeasonSummary `json:"seasons"`
+}
+
+type SeasonSummary struct {
+	SeasonNumber int `json:"season_number"` // 0 for specials
+	EpisodeCount int `json:"episode_count"`
+}
+
+// Season of a TV show on TMDB, with all its episodes.
+type Season struct {
+	SeasonNumber int       `json:"season_number"`
+	Episodes     []Episode `json:"episodes"`
+}
+
+type Episode struct {
+	ID            int    `json:"id"`
+	SeasonNumber  int    `json:"season_number"`
+	EpisodeNumber int    `json:"episode_number"`
+	Name          string `json:"name"`
+	AirDate       string `json:"air_date"` // e.g. 2021-03-15
+}
+
+// SearchTV searches for TV shows on TMDB.
+func (s *client) SearchTV(query string, year int, page int) (*TVSearchResponse, error) {
+	reqURL := fmt.Sprintf(s.baseURL+searchEndpoint, "tv", s.apiKey, url.QueryEscape(s.language))
+
+	v := url.Values{}
+	v.Set("query", query)
+	if year > 0 {
+		v.Add("first_air_date_year", strconv.Itoa(year))
+	}
+	if page > 0 {
+		v.Add("page", strconv.Itoa(page))
+	}
+	reqURL = reqURL + "&" + v.Encode()
+
+	var result TVSearchResponse
+	if err := s.get(reqURL, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TV looks up the details of a TV show on TMDB.
+func (s *client) TV(id int) (*TV, error) {
+	var result TV
+	if err := s.get(fmt.Sprintf(s.baseURL+tvEndpoint, id, s.apiKey, url.QueryEscape(s.language)), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Season looks up a season of a TV show and its episodes on TMDB.
+func (s *client) Season(tvID int, season int) (*Season, error) {
+	var result Season
+	if err := s.get(fmt.Sprintf(s.baseURL+seasonEndpoint, tvID, season, s.apiKey, url.QueryEscape(s.language)), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
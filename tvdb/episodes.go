@@ -0,0 +1,44 @@
+package tvdb
+
+import (
+	"fmt"
+)
+
+const episodesEndpoint = "series/%d/episodes?page=%d"
+
+type episodesResponse struct {
+	Links struct {
+		Next *int `json:"next"`
+	} `json:"links"`
+	Episodes []Episode `json:"data"`
+}
+
+type Episode struct {
+	ID                 int    `json:"id"`
+	AiredSeason        int    `json:"airedSeason"`
+	AiredEpisodeNumber int    `json:"airedEpisodeNumber"`
+	AbsoluteNumber     int    `json:"absoluteNumber"`
+	Title              string `json:"episodeName"`
+	FirstAired         string `json:"firstAired"` // e.g. 2021-03-15
+}
+
+// Episodes of a series on TVDB, all pages are fetched.
+func (s *client) Episodes(seriesID int) ([]Episode, error) {
+	err := s.refreshTokenIfNecessary()
+	if err != nil {
+		return nil, fmt.Errorf("jwt token refresh failed: %v", err)
+	}
+
+	episodes := []Episode{}
+	for page := 1; ; {
+		var result episodesResponse
+		if err := s.get(fmt.Sprintf(s.baseURL+episodesEndpoint, seriesID, page), &result); err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, result.Episodes...)
+		if result.Links.Next == nil || *result.Links.Next <= page {
+			return episodes, nil
+		}
+		page = *result.Links.Next
+	}
+}
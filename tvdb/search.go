@@ -2,7 +2,6 @@ package tvdb
 
 import (
 	"fmt"
-	"net/http"
 	"net/url"
 	"strconv"
 )
@@ -14,6 +13,7 @@ type SearchResponse struct {
 }
 
 type SearchResult struct {
+	ID         int    `json:"id"`
 	FirstAired string `json:"firstAired"` // e.g. 1981-01-01
 	Title      string `json:"seriesName"`
 }
@@ -37,21 +37,9 @@ func (s *client) Search(query string) (*SearchResponse, error) {
 	}
 
 	reqURL := fmt.Sprintf(s.baseURL+searchEndpoint, url.PathEscape(query))
-	req, err := http.NewRequest("GET", reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creation of get request failed: %v", err)
-	}
-	s.addHeaders(req)
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("http get failed: %v", err)
-	}
-	defer resp.Body.Close()
-
 	var result SearchResponse
-	if err := unmarshalResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal of response failed: %v", err)
+	if err := s.get(reqURL, &result); err != nil {
+		return nil, err
 	}
 	return &result, nil
 }
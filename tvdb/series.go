@@ -0,0 +1,49 @@
+package tvdb
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	seriesEndpoint     = "series/%d"
+	imdbSearchEndpoint = "search/series?imdbId=%s"
+)
+
+type seriesResponse struct {
+	Series Series `json:"data"`
+}
+
+// Series details from TVDB.
+type Series struct {
+	SearchResult
+	IMDbID string `json:"imdbId"` // e.g. tt0436992
+}
+
+// Series looks up the details of a series on TVDB.
+func (s *client) Series(id int) (*Series, error) {
+	err := s.refreshTokenIfNecessary()
+	if err != nil {
+		return nil, fmt.Errorf("jwt token refresh failed: %v", err)
+	}
+
+	var result seriesResponse
+	if err := s.get(fmt.Sprintf(s.baseURL+seriesEndpoint, id), &result); err != nil {
+		return nil, err
+	}
+	return &result.Series, nil
+}
+
+// SearchByIMDbID searches for the series with the given IMDb id on TVDB.
+func (s *client) SearchByIMDbID(imdbID string) (*SearchResponse, error) {
+	err := s.refreshTokenIfNecessary()
+	if err != nil {
+		return nil, fmt.Errorf("jwt token refresh failed: %v", err)
+	}
+
+	var result SearchResponse
+	if err := s.get(fmt.Sprintf(s.baseURL+imdbSearchEndpoint, url.QueryEscape(imdbID)), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
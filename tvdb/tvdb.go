@@ -24,6 +24,9 @@ type client struct {
 
 type Client interface {
 	Search(query string) (*SearchResponse, error)
+	SearchByIMDbID(imdbID string) (*SearchResponse, error)
+	Series(id int) (*Series, error)
+	Episodes(seriesID int) ([]Episode, error)
 }
 
 // New creates a new TMDB client.
@@ -42,10 +45,49 @@ func (s *client) addHeaders(req *http.Request) {
 	req.Header.Add("Content-Type", "application/json")
 }
 
+// get does an authenticated get request and unmarshals the response into result.
+func (s *client) get(reqURL string, result interface{}) error {
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("creation of get request failed: %v", err)
+	}
+	s.addHeaders(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http get failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := unmarshalResponse(resp, result); err != nil {
+		if _, ok := err.(*NotFoundError); ok {
+			return err
+		}
+		return fmt.Errorf("unmarshal of response failed: %v", err)
+	}
+	return nil
+}
+
 type apiError struct {
 	Error string `json:"Error"`
 }
 
+// NotFoundError is returned for 404 responses, which TVDB also
+// sends for searches without any match.
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// IsNotFound tells whether err is a NotFoundError.
+func IsNotFound(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
+
 func unmarshalResponse(resp *http.Response, success interface{}) error {
 	if code := resp.StatusCode; 200 <= code && code <= 299 {
 		if success != nil && resp.StatusCode != 204 {
@@ -56,6 +98,9 @@ func unmarshalResponse(resp *http.Response, success interface{}) error {
 		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
 			return fmt.Errorf("unmarshal of response failed: %v", err)
 		}
+		if resp.StatusCode == http.StatusNotFound {
+			return &NotFoundError{Message: apiErr.Error}
+		}
 		return errors.New(apiErr.Error)
 	}
 	return nil
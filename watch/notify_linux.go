@@ -0,0 +1,39 @@
+package watch
+
+import (
+	"syscall"
+
+	"github.com/florianehmke/plexname/log"
+)
+
+// notify returns a channel that receives a value whenever an entry in dir
+// is created, moved in or written. Only dir itself is watched, changes
+// deeper down are picked up by polling.
+func notify(dir string) (<-chan struct{}, func()) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		log.Warnf("inotify not available, polling only: %v", err)
+		return nil, func() {}
+	}
+	mask := uint32(syscall.IN_CREATE | syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE | syscall.IN_MOVED_FROM)
+	if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		log.Warnf("inotify not available, polling only: %v", err)
+		syscall.Close(fd)
+		return nil, func() {}
+	}
+
+	events := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			if _, err := syscall.Read(fd, buf); err != nil {
+				return
+			}
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return events, func() { syscall.Close(fd) }
+}
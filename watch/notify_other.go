@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package watch
+
+// notify is not supported on this platform, the watcher only polls.
+func notify(dir string) (<-chan struct{}, func()) {
+	return nil, func() {}
+}
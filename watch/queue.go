@@ -0,0 +1,94 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/florianehmke/plexname/config"
+)
+
+// Item that could not be renamed without asking and waits for review.
+type Item struct {
+	Path      string    `json:"path"`
+	Target    string    `json:"target"`
+	Args      []string  `json:"args"` // options of the watch run
+	Questions []string  `json:"questions"`
+	Queued    time.Time `json:"queued"`
+}
+
+// Queue of items waiting for review, stored as a file.
+type Queue struct {
+	path string
+}
+
+// DefaultQueue returns the queue in the plexname data directory.
+func DefaultQueue() *Queue {
+	return &Queue{path: filepath.Join(config.DataDir(), "review.json")}
+}
+
+// NewQueue returns the queue stored in the given file.
+func NewQueue(path string) *Queue {
+	return &Queue{path: path}
+}
+
+// Items returns all queued items, oldest first.
+func (q *Queue) Items() ([]Item, error) {
+	var items []Item
+	data, err := ioutil.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return items, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read review queue: %v", err)
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("could not parse review queue: %v", err)
+	}
+	return items, nil
+}
+
+// Add an item, an item for the same path replaces the old one.
+func (q *Queue) Add(item Item) error {
+	items, err := q.Items()
+	if err != nil {
+		return err
+	}
+	return q.save(append(without(items, item.Path), item))
+}
+
+// Remove the item with the given path.
+func (q *Queue) Remove(path string) error {
+	items, err := q.Items()
+	if err != nil {
+		return err
+	}
+	return q.save(without(items, path))
+}
+
+func (q *Queue) save(items []Item) error {
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("could not create queue directory: %v", err)
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal of review queue failed: %v", err)
+	}
+	if err := ioutil.WriteFile(q.path, data, 0644); err != nil {
+		return fmt.Errorf("could not write review queue: %v", err)
+	}
+	return nil
+}
+
+func without(items []Item, path string) []Item {
+	var result []Item
+	for _, i := range items {
+		if i.Path != path {
+			result = append(result, i)
+		}
+	}
+	return result
+}
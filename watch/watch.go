@@ -0,0 +1,179 @@
+// Package watch notices new downloads and hands them over once they are complete.
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/florianehmke/plexname/log"
+)
+
+// incompleteSuffixes mark files that are still being downloaded.
+var incompleteSuffixes = []string{".part", ".!qb", ".!ut", ".crdownload"}
+
+// Handler is called for every new item that stopped changing. It tells
+// if the item is done with, e.g. renamed or queued for review, an item
+// that failed is handed over again once it settled for another while.
+type Handler func(path string) bool
+
+// Watcher polls a directory for new files and folders.
+//
+// An item is handed to the handler once its size and modification time
+// did not change for the settle duration and it contains no incomplete
+// marker files. Items that exist when the watcher starts are ignored.
+type Watcher struct {
+	dir      string
+	interval time.Duration
+	settle   time.Duration
+	handle   Handler
+
+	seen    map[string]bool
+	pending map[string]*candidate
+}
+
+type candidate struct {
+	snapshot snapshot
+	since    time.Time
+}
+
+type snapshot struct {
+	size       int64
+	modTime    time.Time
+	incomplete bool
+}
+
+// New creates a watcher for dir.
+func New(dir string, interval, settle time.Duration, handle Handler) *Watcher {
+	return &Watcher{
+		dir:      dir,
+		interval: interval,
+		settle:   settle,
+		handle:   handle,
+		seen:     map[string]bool{},
+		pending:  map[string]*candidate{},
+	}
+}
+
+// Run watches until stop is closed.
+//
+// The directory is polled every interval, on platforms with file system
+// notifications a change additionally triggers an immediate poll.
+func (w *Watcher) Run(stop <-chan struct{}) error {
+	if err := w.ignoreExisting(); err != nil {
+		return err
+	}
+
+	events, closeNotifier := notify(w.dir)
+	defer closeNotifier()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		case <-events:
+		}
+		w.poll(time.Now())
+	}
+}
+
+func (w *Watcher) ignoreExisting() error {
+	entries, err := ioutil.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		w.seen[e.Name()] = true
+	}
+	return nil
+}
+
+func (w *Watcher) poll(now time.Time) {
+	entries, err := ioutil.ReadDir(w.dir)
+	if err != nil {
+		log.Warnf("Could not scan %s: %v", w.dir, err)
+		return
+	}
+
+	present := map[string]bool{}
+	for _, e := range entries {
+		name := e.Name()
+		present[name] = true
+		if w.seen[name] || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.Join(w.dir, name)
+		s, err := takeSnapshot(path)
+		if err != nil {
+			continue
+		}
+
+		c, ok := w.pending[name]
+		if !ok || c.snapshot != s {
+			if !ok {
+				log.Infof("Noticed: %s", path)
+			}
+			w.pending[name] = &candidate{snapshot: s, since: now}
+			continue
+		}
+		if s.incomplete || now.Sub(c.since) < w.settle {
+			continue
+		}
+
+		if !w.handle(path) {
+			w.pending[name] = &candidate{snapshot: s, since: now}
+			continue
+		}
+		delete(w.pending, name)
+		w.seen[name] = true
+	}
+
+	// Forget items that vanished, e.g. because the handler moved them.
+	for name := range w.seen {
+		if !present[name] {
+			delete(w.seen, name)
+		}
+	}
+	for name := range w.pending {
+		if !present[name] {
+			delete(w.pending, name)
+		}
+	}
+}
+
+func takeSnapshot(path string) (snapshot, error) {
+	var s snapshot
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		s.size += info.Size()
+		if info.ModTime().After(s.modTime) {
+			s.modTime = info.ModTime()
+		}
+		if isIncomplete(info.Name()) {
+			s.incomplete = true
+		}
+		return nil
+	})
+	return s, err
+}
+
+func isIncomplete(name string) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range incompleteSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "plexname-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	write := func(name, content string) {
+		p := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("old.mkv", "old")
+
+	var handled []string
+	w := New(tmp, time.Second, time.Minute, func(path string) bool {
+		handled = append(handled, filepath.Base(path))
+		return true
+	})
+	if err := w.ignoreExisting(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	write("new/movie.mkv", "new")
+	write("new/movie.mkv.part", "")
+	w.poll(start)
+	w.poll(start.Add(2 * time.Minute))
+	if len(handled) != 0 {
+		t.Fatalf("expected incomplete item to be held back, got %v", handled)
+	}
+
+	// The download finished, the item has to settle again.
+	os.Remove(filepath.Join(tmp, "new", "movie.mkv.part"))
+	w.poll(start.Add(3 * time.Minute))
+	w.poll(start.Add(3*time.Minute + 30*time.Second))
+	if len(handled) != 0 {
+		t.Fatalf("expected unsettled item to be held back, got %v", handled)
+	}
+
+	w.poll(start.Add(4 * time.Minute))
+	if len(handled) != 1 || handled[0] != "new" {
+		t.Fatalf("expected only the new item to be handled, got %v", handled)
+	}
+
+	w.poll(start.Add(10 * time.Minute))
+	if len(handled) != 1 {
+		t.Fatalf("expected the new item to be handled once, got %v", handled)
+	}
+}
+
+func TestPoll_Retry(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "plexname-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	var attempts int
+	w := New(tmp, time.Second, time.Minute, func(path string) bool {
+		attempts++
+		return attempts > 1
+	})
+	if err := w.ignoreExisting(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tmp, "movie.mkv"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	w.poll(start)
+	w.poll(start.Add(2 * time.Minute))
+	if attempts != 1 {
+		t.Fatalf("expected one attempt, got %d", attempts)
+	}
+
+	// The failed item has to settle again before it is retried.
+	w.poll(start.Add(2*time.Minute + 30*time.Second))
+	if attempts != 1 {
+		t.Fatalf("expected the retry to wait, got %d attempts", attempts)
+	}
+	w.poll(start.Add(4 * time.Minute))
+	w.poll(start.Add(10 * time.Minute))
+	if attempts != 2 {
+		t.Fatalf("expected the item to be retried once, got %d attempts", attempts)
+	}
+}